@@ -8,8 +8,12 @@ import (
 	"time"
 )
 
+// Maximum number of dedupe keys kept, these span all targets so more are kept than permalinks
+const maxDedupeKeys = 1000
+
 type Cache struct {
 	ProcessedPermalinks map[string]bool `json:"processed_permalinks"`
+	DedupeKeys          map[string]time.Time `json:"dedupe_keys"`
 	LastCacheUpdate  time.Time       	`json:"last_cache_update"`
 	LastPersisted       time.Time       `json:"last_persisted"`
 	mu                  sync.Mutex
@@ -18,6 +22,7 @@ type Cache struct {
 func NewCache() *Cache {
 	return &Cache{
 		ProcessedPermalinks: make(map[string]bool),
+		DedupeKeys:          make(map[string]time.Time),
 	}
 }
 
@@ -38,6 +43,10 @@ func (c *Cache) Load(filename string) error {
 	if err := json.NewDecoder(file).Decode(c); err != nil {
 		return err
 	}
+	// Caches persisted before dedupe keys were introduced
+	if c.DedupeKeys == nil {
+		c.DedupeKeys = make(map[string]time.Time)
+	}
 
 	fileInfo, err := os.Stat(filename)
 	if err == nil {
@@ -97,6 +106,43 @@ func (c *Cache) IsProcessed(permalink string) bool {
 	return c.ProcessedPermalinks[permalink]
 }
 
+// SeenOrRecord reports whether any of the dedupe keys of a post was seen before. As a side effect,
+// all of the keys are recorded as seen now, so a later post sharing any of them is reported as seen.
+func (c *Cache) SeenOrRecord(keys []string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	duplicate := false
+	for _, key := range keys {
+		if _, ok := c.DedupeKeys[key]; ok {
+			duplicate = true
+		}
+	}
+
+	now := time.Now()
+	for _, key := range keys {
+		if _, ok := c.DedupeKeys[key]; !ok && len(c.DedupeKeys) >= maxDedupeKeys {
+			c.evictOldestDedupeKey()
+		}
+		c.DedupeKeys[key] = now
+	}
+	if len(keys) > 0 {
+		c.LastCacheUpdate = now
+	}
+	return duplicate
+}
+
+func (c *Cache) evictOldestDedupeKey() {
+	var oldestKey string
+	var oldest time.Time
+	for key, seen := range c.DedupeKeys {
+		if oldestKey == "" || seen.Before(oldest) {
+			oldestKey, oldest = key, seen
+		}
+	}
+	delete(c.DedupeKeys, oldestKey)
+}
+
 
 func archiveCorruptedCache(cacheFile string) {
     archiveFile := cacheFile + ".archive.bak"
@@ -114,6 +160,7 @@ func (c *Cache) CreateNew(filename string) error {
 
     // Initialize a new cache
     c.ProcessedPermalinks = make(map[string]bool)
+    c.DedupeKeys = make(map[string]time.Time)
     c.LastCacheUpdate = time.Now()
     c.LastPersisted = time.Now()
 
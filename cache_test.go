@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestSeenOrRecord(t *testing.T) {
+	cache := NewCache()
+
+	if cache.SeenOrRecord([]string{"global|id|t3_a", "global|url|example.org/deal"}) {
+		t.Error("first post reported as seen")
+	}
+	// A crosspost of the same link shares the url key
+	if !cache.SeenOrRecord([]string{"global|id|t3_b", "global|url|example.org/deal"}) {
+		t.Error("post sharing a key not reported as seen")
+	}
+	// The keys of the post reported as seen were recorded as well
+	if !cache.SeenOrRecord([]string{"global|id|t3_b"}) {
+		t.Error("keys of a post reported as seen were not recorded")
+	}
+	if cache.SeenOrRecord(nil) {
+		t.Error("post without keys reported as seen")
+	}
+}
+
+func TestSeenOrRecordEvictsOldest(t *testing.T) {
+	cache := NewCache()
+	for i := 0; i < maxDedupeKeys; i++ {
+		cache.DedupeKeys[fmt.Sprintf("key-%d", i)] = time.Now()
+	}
+	cache.DedupeKeys["key-0"] = time.Now().Add(-time.Hour)
+
+	cache.SeenOrRecord([]string{"new"})
+	if len(cache.DedupeKeys) != maxDedupeKeys {
+		t.Errorf("cache holds %d keys, expected %d", len(cache.DedupeKeys), maxDedupeKeys)
+	}
+	if _, ok := cache.DedupeKeys["key-0"]; ok {
+		t.Error("oldest key was not evicted")
+	}
+}
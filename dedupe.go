@@ -0,0 +1,79 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"xenigo/internal/config"
+	"xenigo/internal/reddit"
+)
+
+// dedupeKeys returns the namespaced cache keys used to detect whether the post was already sent within the dedupe scope of the target.
+func dedupeKeys(post reddit.RedditPost, target config.Target) []string {
+	namespace := dedupeNamespace(target)
+
+	var keys []string
+	for _, key := range target.Dedupe.Keys {
+		var value string
+		switch key {
+		case config.DedupeKeyID:
+			value = post.Name
+		case config.DedupeKeyCrosspost:
+			// Crossposts share the key of their original post, so either one is only sent once
+			value = post.CrosspostParent
+			if value == "" {
+				value = post.Name
+			}
+			key = config.DedupeKeyID
+		case config.DedupeKeyURL:
+			value = normalizeURL(post.URL)
+		}
+		if value != "" {
+			keys = append(keys, fmt.Sprintf("%s|%s|%s", namespace, key, value))
+		}
+	}
+	return keys
+}
+
+func dedupeNamespace(target config.Target) string {
+	switch target.Dedupe.Scope {
+	case config.DedupeScopeTarget:
+		return "target:" + target.Name
+	case config.DedupeScopeOutput:
+		// The destination contains secrets, so only a hash of it ends up in the cache file
+		sum := sha256.Sum256([]byte(string(target.Output.Type) + target.Output.Destination()))
+		return "output:" + hex.EncodeToString(sum[:8])
+	default:
+		return "global"
+	}
+}
+
+// normalizeURL strips the parts of an outbound link that differ between submissions of the same link.
+// Links back to Reddit itself, as used by self posts, are ignored.
+func normalizeURL(rawURL string) string {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || parsed.Host == "" {
+		return ""
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(parsed.Host), "www.")
+	host = strings.TrimPrefix(host, "m.")
+	if host == "reddit.com" || strings.HasSuffix(host, ".reddit.com") {
+		return ""
+	}
+
+	query := parsed.Query()
+	for param := range query {
+		if strings.HasPrefix(param, "utm_") || param == "ref" || param == "fbclid" || param == "gclid" {
+			query.Del(param)
+		}
+	}
+
+	normalized := host + strings.TrimSuffix(parsed.EscapedPath(), "/")
+	if encoded := query.Encode(); encoded != "" {
+		normalized += "?" + encoded
+	}
+	return normalized
+}
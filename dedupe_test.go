@@ -0,0 +1,66 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"xenigo/internal/config"
+	"xenigo/internal/reddit"
+)
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{"https://www.example.org/article/", "example.org/article"},
+		{"http://example.org/article", "example.org/article"},
+		{"https://m.example.org/article", "example.org/article"},
+		{"https://EXAMPLE.org/Article", "example.org/Article"},
+		{"https://example.org/article?utm_source=reddit&utm_medium=social&id=7", "example.org/article?id=7"},
+		{"https://example.org/article?ref=front&fbclid=abc&gclid=def", "example.org/article"},
+		{"  https://example.org/article  ", "example.org/article"},
+		{"https://example.org/", "example.org"},
+		{"https://www.reddit.com/r/golang/comments/abc/title/", ""},
+		{"https://old.reddit.com/r/golang/comments/abc/title/", ""},
+		{"/r/golang/comments/abc/title/", ""},
+		{"", ""},
+	}
+	for _, test := range tests {
+		if normalized := normalizeURL(test.url); normalized != test.expected {
+			t.Errorf("normalizeURL(%q) = %q, expected %q", test.url, normalized, test.expected)
+		}
+	}
+}
+
+func TestDedupeKeys(t *testing.T) {
+	target := config.Target{
+		Name:   "deals",
+		Dedupe: &config.Dedupe{Scope: config.DedupeScopeTarget, Keys: []config.DedupeKey{config.DedupeKeyID, config.DedupeKeyCrosspost, config.DedupeKeyURL}},
+	}
+
+	tests := []struct {
+		name     string
+		post     reddit.RedditPost
+		expected []string
+	}{
+		{"link post", reddit.RedditPost{Name: "t3_a", URL: "https://www.example.org/deal/?utm_source=x"}, []string{
+			"target:deals|id|t3_a",
+			"target:deals|id|t3_a",
+			"target:deals|url|example.org/deal",
+		}},
+		{"crosspost shares the id of the original", reddit.RedditPost{Name: "t3_b", CrosspostParent: "t3_a", URL: "https://www.reddit.com/r/deals/comments/a/"}, []string{
+			"target:deals|id|t3_b",
+			"target:deals|id|t3_a",
+		}},
+	}
+	for _, test := range tests {
+		if keys := dedupeKeys(test.post, target); !reflect.DeepEqual(keys, test.expected) {
+			t.Errorf("%s: dedupeKeys() = %q, expected %q", test.name, keys, test.expected)
+		}
+	}
+
+	target.Dedupe.Scope = config.DedupeScopeGlobal
+	if keys := dedupeKeys(reddit.RedditPost{Name: "t3_a"}, target); keys[0] != "global|id|t3_a" {
+		t.Errorf("dedupeKeys() = %q, expected the global namespace", keys)
+	}
+}
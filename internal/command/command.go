@@ -0,0 +1,160 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"xenigo/internal/output"
+	"xenigo/internal/reddit"
+)
+
+// Output of the command beyond this is left out of error messages
+const maxOutputLength = 1000
+
+type CommandSender struct {
+	Command     []string // The program followed by its arguments, no shell is involved
+	Timeout     time.Duration
+	Concurrency int    // Runs of the same command at the same time, across targets
+	Target      string // Passed for digests and held posts, which only carry the embed
+}
+
+// Every command line has its own limit on the number of runs at the same time
+var (
+	slots   = make(map[string]chan struct{})
+	slotsMu sync.Mutex
+)
+
+func acquire(key string, concurrency int) chan struct{} {
+	slotsMu.Lock()
+	slot, ok := slots[key]
+	if !ok {
+		if concurrency < 1 {
+			concurrency = 1
+		}
+		slot = make(chan struct{}, concurrency)
+		slots[key] = slot
+	}
+	slotsMu.Unlock()
+
+	slot <- struct{}{}
+	return slot
+}
+
+// payload is the post as the command receives it on stdin, digests and held posts only carry the embed
+type payload struct {
+	Target string             `json:"target"`
+	Title  string             `json:"title"`
+	URL    string             `json:"url"`
+	Author string             `json:"author"`
+	Post   *reddit.RedditPost `json:"post,omitempty"`
+	Fields map[string]string  `json:"fields,omitempty"`
+}
+
+func newPayload(data output.PostData) payload {
+	p := payload{
+		Target: data.Target,
+		Title:  data.Embed.Title,
+		URL:    data.Embed.URL,
+		Author: data.Embed.Author,
+		Fields: data.Fields,
+	}
+	if post, ok := data.Post.(reddit.RedditPost); ok {
+		p.Post = &post
+	}
+	return p
+}
+
+func (c *CommandSender) SendMessage(embed output.MessageEmbed) error {
+	return c.SendPost(output.PostData{Embed: embed})
+}
+
+// SendPost runs the command with the post as JSON on stdin and as XENIGO_ environment variables.
+// The post counts as sent when the command exits with status 0.
+func (c *CommandSender) SendPost(data output.PostData) error {
+	if data.Target == "" {
+		data.Target = c.Target
+	}
+	log.Printf("Running command for post: %s", data.Embed.Title)
+
+	input, err := json.Marshal(newPayload(data))
+	if err != nil {
+		return fmt.Errorf("failed to marshal post: %w", err)
+	}
+
+	slot := acquire(strings.Join(c.Command, "\x00"), c.Concurrency)
+	defer func() { <-slot }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.Timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, c.Command[0], c.Command[1:]...)
+	// Terminated by a newline, so scripts can read the post as a single line
+	cmd.Stdin = bytes.NewReader(append(input, '\n'))
+	cmd.Env = append(os.Environ(), environment(data)...)
+	// Don't wait forever on children of the command that keep its output open
+	cmd.WaitDelay = 5 * time.Second
+
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	err = cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("command %s timed out after %s", c.Command[0], c.Timeout)
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return fmt.Errorf("command %s exited with status %d: %s", c.Command[0], exitErr.ExitCode(), tail(out.String()))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to run command %s: %w", c.Command[0], err)
+	}
+	return nil
+}
+
+// environment describes the post in variables, e.g. XENIGO_TITLE and XENIGO_FIELD_PRICE
+func environment(data output.PostData) []string {
+	env := map[string]string{
+		"XENIGO_TARGET": data.Target,
+		"XENIGO_TITLE":  data.Embed.Title,
+		"XENIGO_URL":    data.Embed.URL,
+		"XENIGO_AUTHOR": data.Embed.Author,
+	}
+	if post, ok := data.Post.(reddit.RedditPost); ok {
+		env["XENIGO_ID"] = post.Name
+		env["XENIGO_SUBREDDIT"] = post.Subreddit
+		env["XENIGO_DISCUSSION_URL"] = post.DiscussionURL()
+		env["XENIGO_SCORE"] = strconv.Itoa(post.Score)
+		env["XENIGO_NUM_COMMENTS"] = strconv.Itoa(post.NumComments)
+		env["XENIGO_OVER_18"] = strconv.FormatBool(post.Over18)
+	}
+	for name, value := range data.Fields {
+		env["XENIGO_FIELD_"+strings.ToUpper(name)] = value
+	}
+
+	var vars []string
+	for name, value := range env {
+		// Environment variables can't hold NUL bytes
+		vars = append(vars, name+"="+strings.ReplaceAll(value, "\x00", ""))
+	}
+	sort.Strings(vars)
+	return vars
+}
+
+func tail(output string) string {
+	output = strings.TrimSpace(output)
+	if len(output) > maxOutputLength {
+		output = "…" + output[len(output)-maxOutputLength:]
+	}
+	return output
+}
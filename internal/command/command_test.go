@@ -0,0 +1,83 @@
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"xenigo/internal/output"
+	"xenigo/internal/reddit"
+)
+
+func TestSendPost(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+	sender := &CommandSender{
+		Command:     []string{"sh", "-c", `cat > "$0"; echo "$XENIGO_TARGET|$XENIGO_TITLE|$XENIGO_FIELD_PRICE|$XENIGO_SUBREDDIT" >> "$0"`, out},
+		Timeout:     5 * time.Second,
+		Concurrency: 1,
+	}
+	err := sender.SendPost(output.PostData{
+		Target: "GPUs",
+		Post:   reddit.RedditPost{Title: "3080 FE", Subreddit: "hardwareswap"},
+		Fields: map[string]string{"price": "450"},
+		Embed:  output.MessageEmbed{Title: "3080 FE"},
+	})
+	if err != nil {
+		t.Fatalf("SendPost() error = %v", err)
+	}
+
+	data, _ := os.ReadFile(out)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], `{"target":"GPUs","title":"3080 FE","url":"","author":"","post":{"title":"3080 FE"`) {
+		t.Errorf("unexpected stdin %q", data)
+	}
+	if lines[len(lines)-1] != "GPUs|3080 FE|450|hardwareswap" {
+		t.Errorf("unexpected environment %q", lines[len(lines)-1])
+	}
+}
+
+func TestSendMessage(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+	sender := &CommandSender{
+		Command:     []string{"sh", "-c", `cat > "$0"; echo "$XENIGO_TARGET|$XENIGO_TITLE|$XENIGO_URL|$XENIGO_AUTHOR" >> "$0"`, out},
+		Timeout:     5 * time.Second,
+		Concurrency: 1,
+		Target:      "GPUs",
+	}
+	// Digests and held posts only reach the sender as an embed
+	err := sender.SendMessage(output.MessageEmbed{Title: "3080 FE", URL: "https://example.org/post", Author: "someone"})
+	if err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+
+	data, _ := os.ReadFile(out)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 || lines[0] != `{"target":"GPUs","title":"3080 FE","url":"https://example.org/post","author":"someone"}` {
+		t.Errorf("unexpected stdin %q", data)
+	}
+	if lines[len(lines)-1] != "GPUs|3080 FE|https://example.org/post|someone" {
+		t.Errorf("unexpected environment %q", lines[len(lines)-1])
+	}
+}
+
+func TestSendPostFailure(t *testing.T) {
+	tests := []struct {
+		name     string
+		command  []string
+		expected string
+	}{
+		{"exit status", []string{"sh", "-c", "echo broken >&2; exit 3"}, "exited with status 3: broken"},
+		{"timeout", []string{"sleep", "5"}, "timed out"},
+		{"missing", []string{"/nonexistent/command"}, "failed to run command"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender := &CommandSender{Command: tt.command, Timeout: 200 * time.Millisecond, Concurrency: 1}
+			err := sender.SendMessage(output.MessageEmbed{Title: "A post"})
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("SendMessage() error = %v, expected %q", err, tt.expected)
+			}
+		})
+	}
+}
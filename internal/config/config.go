@@ -4,9 +4,19 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"net"
+	"net/http"
+	"net/mail"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
+	"text/template"
+	"time"
+	_ "time/tzdata" // The container image doesn't ship timezone data
+
+	"xenigo/internal/cron"
+	"xenigo/internal/webhook"
 
 	"gopkg.in/yaml.v2"
 )
@@ -17,6 +27,13 @@ const (
 	DefaultLimit         = 3
 	DefaultRetryCount    = 3
 	DefaultRetryInterval = 2
+	DefaultUpdateWindow  = 86400
+	DefaultTrendWindow   = 3600
+	DefaultDigestWindow  = 300
+	DefaultDigestItems   = 10
+	DefaultMinInterval   = 15
+	DefaultMaxInterval   = 600
+	MinimumInterval      = 10 // Seconds, polling more often only hammers Reddit
 )
 
 
@@ -29,11 +46,19 @@ type OAuthConfig struct {
 }
 
 type Options struct {
-	Interval       int  `yaml:"interval"`
-	Limit          int  `yaml:"limit"`
-	RetryCount     int  `yaml:"retry_count"`
-	RetryInterval  int  `yaml:"retry_interval"`
-	EnableFallback bool `yaml:"enable_fallback"`
+	Interval       int       `yaml:"interval"`
+	Limit          int       `yaml:"limit"`
+	RetryCount     int       `yaml:"retry_count"`
+	RetryInterval  int       `yaml:"retry_interval"`
+	EnableFallback bool      `yaml:"enable_fallback"`
+	Schedule       string    `yaml:"schedule,omitempty"` // Cron expression, replaces the interval
+	Adaptive       *Adaptive `yaml:"adaptive,omitempty"`
+}
+
+// Adaptive polls faster while new posts keep coming in and backs off while it's quiet, starting at the interval
+type Adaptive struct {
+	MinInterval int `yaml:"min_interval"` // Seconds
+	MaxInterval int `yaml:"max_interval"` // Seconds
 }
 
 type Config struct {
@@ -57,25 +82,273 @@ type AppConfig struct {
 }
 
 type Target struct {
-	Name    string `yaml:"name"`
-	Monitor struct {
-		Subreddit string `yaml:"subreddit"`
-		Sorting   string `yaml:"sorting"`
-	} `yaml:"monitor"`
-	Output  OutputConfig `yaml:"output"`
-	Options *Options     `yaml:"options,omitempty"`
+	Name         string        `yaml:"name"`
+	Monitor      Monitor       `yaml:"monitor"`
+	Output       OutputConfig  `yaml:"output"`
+	Options      *Options      `yaml:"options,omitempty"`
+	TrackUpdates *TrackUpdates `yaml:"track_updates,omitempty"`
+	Trending     *Trending     `yaml:"trending,omitempty"`
+	Content      *Content      `yaml:"content,omitempty"`
+	Dedupe       *Dedupe       `yaml:"dedupe,omitempty"`
+	Parser       ParserType    `yaml:"parser,omitempty"`
+	Filters      []Filter      `yaml:"filters,omitempty"`
+	Keywords     []Keyword     `yaml:"keywords,omitempty"`
+	Mentions     []MentionRule `yaml:"mentions,omitempty"`
+	Delivery     *Delivery     `yaml:"delivery,omitempty"`
+}
+
+type DeliveryMode string
+
+const (
+	DeliveryImmediate DeliveryMode = "immediate"
+	DeliveryDigest    DeliveryMode = "digest"
+)
+
+// Delivery controls whether posts are sent one by one or collected into a digest
+type Delivery struct {
+	Mode     DeliveryMode `yaml:"mode"`
+	Window   int          `yaml:"window"`    // Seconds between digests
+	MaxItems int          `yaml:"max_items"` // A digest is sent early once it holds this many posts
+}
+
+// MentionRule pings the given Discord roles/users or Slack user groups/users when the field of a post matches
+type MentionRule struct {
+	Match        string   `yaml:"match"` // Regular expression, case insensitive
+	Field        string   `yaml:"field"` // Any field available to filters, defaults to title
+	DiscordRoles []string `yaml:"discord_roles,omitempty"`
+	DiscordUsers []string `yaml:"discord_users,omitempty"`
+	SlackGroups  []string `yaml:"slack_groups,omitempty"`
+	SlackUsers   []string `yaml:"slack_users,omitempty"`
+
+	pattern *regexp.Regexp // Match, compiled when the config is validated
+}
+
+// Pattern returns the case insensitive match pattern, which is compiled once when the config is validated.
+func (m MentionRule) Pattern() (*regexp.Regexp, error) {
+	if m.pattern != nil {
+		return m.pattern, nil
+	}
+	return compilePattern(m.Match)
+}
+
+// Keyword only lets posts through that mention any of the keywords, optionally below a price ceiling.
+// With the market parser only the have part of the title is matched.
+type Keyword struct {
+	Match    string  `yaml:"match"`
+	MaxPrice float64 `yaml:"max_price,omitempty"`
+}
+
+// ParserType extracts structured data from post titles for use in filters and notifications
+type ParserType string
+
+const (
+	ParserMarket ParserType = "market" // [LOCATION] [H] have [W] want, as used by trading subreddits
+)
+
+// Filter only lets posts through whose field matches all of the set conditions, all filters of a target must match.
+// Matching is case insensitive.
+type Filter struct {
+	Field      string   `yaml:"field"`
+	Contains   string   `yaml:"contains,omitempty"`
+	StartsWith string   `yaml:"starts_with,omitempty"`
+	Equals     string   `yaml:"equals,omitempty"`
+	Matches    string   `yaml:"matches,omitempty"` // Regular expression
+	Min        *float64 `yaml:"min,omitempty"`     // Numeric fields such as price only
+	Max        *float64 `yaml:"max,omitempty"`     // Numeric fields such as price only
+
+	pattern *regexp.Regexp // Matches, compiled when the config is validated
+}
+
+// Pattern returns the case insensitive matches pattern, which is compiled once when the config is validated.
+func (f Filter) Pattern() (*regexp.Regexp, error) {
+	if f.pattern != nil {
+		return f.pattern, nil
+	}
+	return compilePattern(f.Matches)
+}
+
+// compilePattern compiles the regular expression of a filter or mention rule, these match case insensitively
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile("(?i)" + pattern)
+}
+
+// Dedupe suppresses posts that were already sent within the scope, matched on any of the keys
+type Dedupe struct {
+	Scope DedupeScope `yaml:"scope"`
+	Keys  []DedupeKey `yaml:"keys"`
+}
+
+type DedupeScope string
+
+const (
+	DedupeScopeTarget DedupeScope = "target"
+	DedupeScopeOutput DedupeScope = "output"
+	DedupeScopeGlobal DedupeScope = "global"
+)
+
+type DedupeKey string
+
+const (
+	DedupeKeyID        DedupeKey = "id"
+	DedupeKeyCrosspost DedupeKey = "crosspost" // The original post for crossposts, the post itself otherwise
+	DedupeKeyURL       DedupeKey = "url"       // The normalized outbound link, self posts are ignored
+)
+
+// ContentFilter controls whether posts with a given property are included, excluded or exclusively sent
+type ContentFilter string
+
+const (
+	ContentInclude ContentFilter = "include"
+	ContentExclude ContentFilter = "exclude"
+	ContentOnly    ContentFilter = "only"
+)
+
+type Content struct {
+	Stickied   ContentFilter `yaml:"stickied"`
+	NSFW       ContentFilter `yaml:"nsfw"`
+	Spoiler    ContentFilter `yaml:"spoiler"`
+	Crossposts ContentFilter `yaml:"crossposts"`
+	BlurNSFW   bool          `yaml:"blur_nsfw"` // Hide NSFW content behind a spoiler where the output supports it
+}
+
+// Trending sends an additional notification once a post gains the given number of
+// upvotes or comments within the window, each threshold fires at most once per post.
+// Posts are followed for 24 hours after they were first seen, also after they left the listing
+type Trending struct {
+	Score    int `yaml:"score"`
+	Comments int `yaml:"comments"`
+	Window   int `yaml:"window"` // Seconds
+}
+
+// TrackUpdates re-checks sent posts for edits, deletions, removals and flair changes
+type TrackUpdates struct {
+	Window int `yaml:"window"` // Seconds a post is tracked after it was first sent
+}
+
+// Monitor describes what a target watches: a subreddit (or several joined with "+"),
+// a user's feed or a user's multireddit.
+type Monitor struct {
+	Subreddit   string              `yaml:"subreddit,omitempty"`
+	User        *UserMonitor        `yaml:"user,omitempty"`
+	Multireddit *MultiredditMonitor `yaml:"multireddit,omitempty"`
+	Sorting     string              `yaml:"sorting"`
+	TimeFilter  string              `yaml:"time_filter,omitempty"`
+	Moderation  ModerationFeed      `yaml:"moderation,omitempty"`
+	Inbox       InboxFeed           `yaml:"inbox,omitempty"`
+	MarkRead    bool                `yaml:"mark_read,omitempty"`
+}
+
+// InboxFeed selects one of the inbox feeds of the OAuth account, requires OAuth
+type InboxFeed string
+
+const (
+	InboxUnread   InboxFeed = "unread"
+	InboxMentions InboxFeed = "mentions"
+)
+
+// ModerationFeed selects one of the moderator-only feeds of a subreddit, requires OAuth
+type ModerationFeed string
+
+const (
+	ModerationModqueue ModerationFeed = "modqueue"
+	ModerationReports  ModerationFeed = "reports"
+	ModerationSpam     ModerationFeed = "spam"
+	ModerationLog      ModerationFeed = "log"
+	ModerationModmail  ModerationFeed = "modmail"
+)
+
+// Sorting options
+const (
+	SortingHot           = "hot"
+	SortingNew           = "new"
+	SortingTop           = "top"
+	SortingControversial = "controversial"
+	SortingRising        = "rising"
+	SortingBest          = "best"
+)
+
+// Time filters, only applicable to top and controversial sorting
+const (
+	TimeFilterHour  = "hour"
+	TimeFilterDay   = "day"
+	TimeFilterWeek  = "week"
+	TimeFilterMonth = "month"
+	TimeFilterYear  = "year"
+	TimeFilterAll   = "all"
+)
+
+type UserFeed string
+
+const (
+	UserFeedSubmitted UserFeed = "submitted"
+	UserFeedComments  UserFeed = "comments"
+	UserFeedOverview  UserFeed = "overview"
+)
+
+type UserMonitor struct {
+	Name string   `yaml:"name"`
+	Feed UserFeed `yaml:"feed"`
+}
+
+type MultiredditMonitor struct {
+	User string `yaml:"user"`
+	Name string `yaml:"name"`
+}
+
+// String returns a short human readable label for the monitored source, e.g. "r/cats" or "u/spez/submitted".
+func (m Monitor) String() string {
+	if m.User != nil {
+		return fmt.Sprintf("u/%s/%s", m.User.Name, m.User.Feed)
+	}
+	if m.Multireddit != nil {
+		return fmt.Sprintf("u/%s/m/%s", m.Multireddit.User, m.Multireddit.Name)
+	}
+	if m.Moderation != "" {
+		return fmt.Sprintf("r/%s/%s", m.Subreddit, m.Moderation)
+	}
+	if m.Inbox != "" {
+		return fmt.Sprintf("inbox/%s", m.Inbox)
+	}
+	return "r/" + m.Subreddit
 }
 
 type OutputType string
 
 const (
-	OutputTypeDiscord OutputType = "discord"
-	OutputTypeSlack   OutputType = "slack"
+	OutputTypeDiscord    OutputType = "discord"
+	OutputTypeSlack      OutputType = "slack"
+	OutputTypeTelegram   OutputType = "telegram"
+	OutputTypeMatrix     OutputType = "matrix"
+	OutputTypeTeams      OutputType = "teams"
+	OutputTypeWebhook    OutputType = "webhook"
+	OutputTypeEmail      OutputType = "email"
+	OutputTypeNtfy       OutputType = "ntfy"
+	OutputTypeGotify     OutputType = "gotify"
+	OutputTypePushover   OutputType = "pushover"
+	OutputTypeMattermost OutputType = "mattermost"
+	OutputTypeRocketChat OutputType = "rocketchat"
+	OutputTypeFile       OutputType = "file"
+	OutputTypeStdout     OutputType = "stdout"
+	OutputTypeCommand    OutputType = "command"
+	OutputTypeIRC        OutputType = "irc"
 )
 
 type OutputConfig struct {
-	Type       OutputType `yaml:"type"`
-	WebhookURL string     `yaml:"webhook_url"`
+	Type       OutputType        `yaml:"type"`
+	WebhookURL string            `yaml:"webhook_url"`
+	Telegram   *TelegramOutput   `yaml:"telegram,omitempty"`
+	Matrix     *MatrixOutput     `yaml:"matrix,omitempty"`
+	Webhook    *WebhookOutput    `yaml:"webhook,omitempty"`
+	Email      *EmailOutput      `yaml:"email,omitempty"`
+	Ntfy       *NtfyOutput       `yaml:"ntfy,omitempty"`
+	Gotify     *GotifyOutput     `yaml:"gotify,omitempty"`
+	Pushover   *PushoverOutput   `yaml:"pushover,omitempty"`
+	Mattermost *MattermostOutput `yaml:"mattermost,omitempty"`
+	RocketChat *RocketChatOutput `yaml:"rocketchat,omitempty"`
+	File       *FileOutput       `yaml:"file,omitempty"`
+	Command    *CommandOutput    `yaml:"command,omitempty"`
+	IRC        *IRCOutput        `yaml:"irc,omitempty"`
+	Schedule   *OutputSchedule   `yaml:"schedule,omitempty"`
 	Format     struct {
 		URL           *bool `yaml:"url"`
 		Author        *bool `yaml:"author"`
@@ -84,6 +357,233 @@ type OutputConfig struct {
 	} `yaml:"format"`
 }
 
+// Destination identifies where the output delivers to, e.g. for de-duplication per output
+func (o OutputConfig) Destination() string {
+	switch o.Type {
+	case OutputTypeTelegram:
+		if o.Telegram != nil {
+			return o.Telegram.APIURL + "/" + o.Telegram.BotToken + "/" + o.Telegram.ChatID
+		}
+	case OutputTypeFile:
+		if o.File != nil {
+			return o.File.Path
+		}
+	case OutputTypeStdout:
+		return "stdout"
+	case OutputTypeIRC:
+		if o.IRC != nil {
+			return o.IRC.Server + "/" + strings.Join(o.IRC.Channels, ",")
+		}
+	case OutputTypeCommand:
+		if o.Command != nil {
+			return strings.Join(o.Command.Run, " ")
+		}
+	case OutputTypeMatrix:
+		if o.Matrix != nil {
+			return o.Matrix.HomeserverURL + "/" + o.Matrix.RoomID
+		}
+	case OutputTypeEmail:
+		if o.Email != nil {
+			return o.Email.Host + "/" + strings.Join(o.Email.To, ",")
+		}
+	case OutputTypeNtfy:
+		if o.Ntfy != nil {
+			return o.Ntfy.ServerURL + "/" + o.Ntfy.Topic
+		}
+	case OutputTypeGotify:
+		if o.Gotify != nil {
+			return o.Gotify.ServerURL + "/" + o.Gotify.AppToken
+		}
+	case OutputTypePushover:
+		if o.Pushover != nil {
+			return o.Pushover.APIURL + "/" + o.Pushover.UserKey + "/" + o.Pushover.Device
+		}
+	}
+	return o.WebhookURL
+}
+
+type TelegramOutput struct {
+	BotToken   string `yaml:"bot_token"`
+	ChatID     string `yaml:"chat_id"`
+	ParseMode  string `yaml:"parse_mode"`  // HTML or MarkdownV2, defaults to HTML
+	SendPhotos bool   `yaml:"send_photos"` // Send image posts as photo messages
+	APIURL     string `yaml:"api_url"`     // Defaults to https://api.telegram.org
+}
+
+const (
+	TelegramParseModeHTML       = "HTML"
+	TelegramParseModeMarkdownV2 = "MarkdownV2"
+	DefaultTelegramAPIURL       = "https://api.telegram.org"
+)
+
+type MatrixOutput struct {
+	HomeserverURL string `yaml:"homeserver_url"`
+	AccessToken   string `yaml:"access_token"`
+	RoomID        string `yaml:"room_id"` // Internal room id, e.g. !abcdef:matrix.org
+}
+
+// WebhookOutput sends posts to the webhook_url of the output as JSON
+type WebhookOutput struct {
+	Method         string            `yaml:"method"`          // Defaults to POST
+	Headers        map[string]string `yaml:"headers"`         // Values may reference environment variables as ${NAME}
+	ExpectedStatus []int             `yaml:"expected_status"` // Defaults to any 2xx status
+	Body           string            `yaml:"body"`            // JSON body template, defaults to the post data as JSON
+
+	template *template.Template // Body, parsed when the config is validated
+}
+
+// BodyTemplate returns the parsed body template, which is parsed once when the config is validated.
+// Without a body template nil is returned.
+func (w WebhookOutput) BodyTemplate() (*template.Template, error) {
+	if w.template != nil {
+		return w.template, nil
+	}
+	return webhook.ParseTemplate(w.Body)
+}
+
+// methodPattern matches the token an HTTP method consists of
+var methodPattern = regexp.MustCompile("^[!#$%&'*+.^_`|~0-9A-Za-z-]+$")
+
+type EmailOutput struct {
+	Host     string   `yaml:"host"`
+	Port     int      `yaml:"port"`     // Defaults to 587 for starttls, 465 for tls and 25 for none
+	Security string   `yaml:"security"` // starttls, tls or none, defaults to starttls
+	Username string   `yaml:"username"` // Optional, e.g. for local relays
+	Password string   `yaml:"password"`
+	From     string   `yaml:"from"`
+	To       []string `yaml:"to"`
+}
+
+const (
+	EmailSecurityStartTLS = "starttls"
+	EmailSecurityTLS      = "tls"
+	EmailSecurityNone     = "none"
+)
+
+type NtfyOutput struct {
+	ServerURL string   `yaml:"server_url"` // Defaults to https://ntfy.sh
+	Topic     string   `yaml:"topic"`
+	Token     string   `yaml:"token"`    // Access token for protected topics
+	Priority  int      `yaml:"priority"` // 1 (min) to 5 (max), defaults to the server default of 3
+	Tags      []string `yaml:"tags"`     // Tags and emoji shortcodes shown with the notification
+}
+
+type GotifyOutput struct {
+	ServerURL string `yaml:"server_url"`
+	AppToken  string `yaml:"app_token"`
+	Priority  *int   `yaml:"priority"` // 0 to 10, defaults to the default priority of the application
+}
+
+type PushoverOutput struct {
+	APIURL   string `yaml:"api_url"` // Defaults to https://api.pushover.net
+	AppToken string `yaml:"app_token"`
+	UserKey  string `yaml:"user_key"` // User or group key
+	Priority int    `yaml:"priority"` // -2 (lowest) to 1 (high), defaults to 0
+	Sound    string `yaml:"sound"`
+	Device   string `yaml:"device"`
+}
+
+// MattermostOutput overrides the defaults of the incoming webhook, which has to allow overrides
+type MattermostOutput struct {
+	Username string `yaml:"username"`
+	IconURL  string `yaml:"icon_url"`
+	Channel  string `yaml:"channel"`
+}
+
+// RocketChatOutput overrides the defaults of the incoming webhook
+type RocketChatOutput struct {
+	Alias   string `yaml:"alias"`
+	Avatar  string `yaml:"avatar"` // Image URL
+	Emoji   string `yaml:"emoji"`  // Used instead of the avatar, e.g. :ghost:
+	Channel string `yaml:"channel"`
+}
+
+// FileOutput archives posts, the format also applies to the stdout output
+type FileOutput struct {
+	Path    string `yaml:"path"`
+	Format  string `yaml:"format"`   // jsonl, csv or markdown, defaults to jsonl
+	Rotate  string `yaml:"rotate"`   // none, size or daily, defaults to none
+	MaxSize int    `yaml:"max_size"` // Size in MB after which the file is rotated, defaults to 10
+}
+
+const (
+	FileFormatJSONL    = "jsonl"
+	FileFormatCSV      = "csv"
+	FileFormatMarkdown = "markdown"
+	FileRotateNone     = "none"
+	FileRotateSize     = "size"
+	FileRotateDaily    = "daily"
+	DefaultFileMaxSize = 10
+)
+
+// CommandOutput runs a command for each post, which counts as sent when the command exits with status 0
+type CommandOutput struct {
+	Run         []string `yaml:"run"`         // The program followed by its arguments, no shell is involved
+	Timeout     int      `yaml:"timeout"`     // Seconds, defaults to 30
+	Concurrency int      `yaml:"concurrency"` // Runs of the same command at the same time across targets, defaults to 1
+}
+
+type IRCOutput struct {
+	Server           string   `yaml:"server"` // host:port, the port defaults to 6697 with TLS and 6667 without
+	TLS              bool     `yaml:"tls"`
+	Nick             string   `yaml:"nick"`
+	Username         string   `yaml:"username"`  // Defaults to the nick
+	RealName         string   `yaml:"real_name"` // Defaults to xenigo
+	Password         string   `yaml:"password"`  // Server password
+	SASLUsername     string   `yaml:"sasl_username"`
+	SASLPassword     string   `yaml:"sasl_password"`
+	NickServPassword string   `yaml:"nickserv_password"`
+	Channels         []string `yaml:"channels"`    // "#channel" or "#channel key"
+	FloodBurst       int      `yaml:"flood_burst"` // Lines sent right away, defaults to 4
+	FloodDelay       int      `yaml:"flood_delay"` // Milliseconds between lines after the burst, defaults to 2000
+}
+
+const (
+	DefaultIRCFloodBurst = 4
+	DefaultIRCFloodDelay = 2000
+)
+
+const (
+	DefaultCommandTimeout     = 30
+	DefaultCommandConcurrency = 1
+)
+
+const (
+	DefaultNtfyServerURL  = "https://ntfy.sh"
+	DefaultPushoverAPIURL = "https://api.pushover.net"
+)
+
+// OutputSchedule holds back posts during quiet hours, held posts are sent as a digest once quiet hours end
+type OutputSchedule struct {
+	Timezone    string      `yaml:"timezone"` // IANA name such as Europe/Amsterdam, defaults to UTC
+	QuietHours  []TimeRange `yaml:"quiet_hours"`
+	DuringQuiet QuietAction `yaml:"during_quiet"`
+}
+
+// TimeRange is a daily time range, ranges ending before they start wrap past midnight
+type TimeRange struct {
+	Days  []string `yaml:"days"`  // mon, tue, wed, thu, fri, sat, sun, defaults to every day
+	Start string   `yaml:"start"` // HH:MM
+	End   string   `yaml:"end"`   // HH:MM
+}
+
+var Weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+type QuietAction string
+
+const (
+	QuietHold QuietAction = "hold"
+	QuietDrop QuietAction = "drop"
+)
+
 func loadConfigFile(filename string) (*Config, error) {
 	path := filepath.Join("config", filename)
 	log.Printf("Checking path: %s", path)
@@ -108,17 +608,12 @@ func loadConfigFile(filename string) (*Config, error) {
 	setGlobalDefaults(&config)
 	setDeveloperFlagsDefaults(&config)
 
+	for i := range config.Targets {
+		setMonitorDefaults(&config.Targets[i].Monitor)
+	}
+	setTargetNames(&config)
+
 	for i, target := range config.Targets {
-		if target.Monitor.Subreddit == "" || target.Monitor.Sorting == "" {
-			return nil, errors.New("monitor block is not correctly configured")
-		}
-		if target.Output.WebhookURL == "" {
-			return nil, errors.New("output block is not correctly configured")
-		}
-		
-		if target.Name == "" {
-			config.Targets[i].Name = target.Monitor.Subreddit
-		}
 		// Initialize Format using the helper function
 		config.Targets[i].Output.Format = initializeFormat(target.Output.Format)
 		// Check if all format options are set to false
@@ -133,6 +628,30 @@ func loadConfigFile(filename string) (*Config, error) {
 	return &config, nil
 }
 
+// setTargetNames names the targets without a name after what they monitor. Digests and the target
+// dedupe scope are keyed by name, so a number is appended when the name is already taken.
+func setTargetNames(config *Config) {
+	names := make(map[string]bool)
+	for _, target := range config.Targets {
+		names[target.Name] = true
+	}
+	for i := range config.Targets {
+		target := &config.Targets[i]
+		if target.Name != "" {
+			continue
+		}
+		base := target.Monitor.Subreddit
+		if base == "" {
+			base = target.Monitor.String()
+		}
+		target.Name = base
+		for n := 2; names[target.Name]; n++ {
+			target.Name = fmt.Sprintf("%s-%d", base, n)
+		}
+		names[target.Name] = true
+	}
+}
+
 func validateConfig(config *Config) error {
 	if config.UserAgent == "" {
 		return errors.New("user_agent is required")
@@ -142,17 +661,444 @@ func validateConfig(config *Config) error {
 			return errors.New("oauth block is not correctly configured")
 		}
 	}
+	if err := validateOptions(config.Options); err != nil {
+		return err
+	}
+	names := make(map[string]bool)
 	for _, target := range config.Targets {
-		if target.Monitor.Subreddit == "" || target.Monitor.Sorting == "" {
-			return errors.New("monitor block is not correctly configured")
+		if target.Name != "" && names[target.Name] {
+			return fmt.Errorf("target name %q is used more than once, target names must be unique", target.Name)
+		}
+		names[target.Name] = true
+	}
+	for _, target := range config.Targets {
+		if err := validateMonitor(target.Monitor); err != nil {
+			return err
+		}
+		if err := validateOptions(target.Options); err != nil {
+			return fmt.Errorf("options of target %s: %w", target.Name, err)
+		}
+		if target.Monitor.Moderation != "" && config.OAuth == nil {
+			return fmt.Errorf("moderation feed %q requires the oauth block to be configured", target.Monitor.Moderation)
+		}
+		if target.Monitor.Inbox != "" && config.OAuth == nil {
+			return fmt.Errorf("inbox feed %q requires the oauth block to be configured", target.Monitor.Inbox)
+		}
+		if target.TrackUpdates != nil && (target.Monitor.Inbox != "" || target.Monitor.Moderation == ModerationLog || target.Monitor.Moderation == ModerationModmail) {
+			return fmt.Errorf("track_updates is not supported for %s", target.Monitor)
 		}
-		if target.Output.WebhookURL == "" {
+		if target.Trending != nil && target.Trending.Score <= 0 && target.Trending.Comments <= 0 {
+			return errors.New("trending block requires a score or comments threshold")
+		}
+		if target.Parser != "" && target.Parser != ParserMarket {
+			return fmt.Errorf("unsupported parser %q, must be market", target.Parser)
+		}
+		// The slices are shared with the config, so the compiled patterns are kept
+		for i := range target.Filters {
+			if err := validateFilter(&target.Filters[i]); err != nil {
+				return err
+			}
+		}
+		for i := range target.Mentions {
+			mention := &target.Mentions[i]
+			if mention.Match == "" {
+				return errors.New("mention rule requires a match")
+			}
+			pattern, err := compilePattern(mention.Match)
+			if err != nil {
+				return fmt.Errorf("invalid mention pattern %q: %w", mention.Match, err)
+			}
+			mention.pattern = pattern
+		}
+		for _, keyword := range target.Keywords {
+			if keyword.Match == "" {
+				return errors.New("keyword requires a match")
+			}
+			if keyword.MaxPrice < 0 {
+				return fmt.Errorf("max_price of keyword %q can't be negative", keyword.Match)
+			}
+		}
+		if target.Dedupe != nil {
+			switch target.Dedupe.Scope {
+			case "", DedupeScopeTarget, DedupeScopeOutput, DedupeScopeGlobal:
+			default:
+				return fmt.Errorf("unsupported dedupe scope %q, must be one of target, output or global", target.Dedupe.Scope)
+			}
+			for _, key := range target.Dedupe.Keys {
+				switch key {
+				case DedupeKeyID, DedupeKeyCrosspost, DedupeKeyURL:
+				default:
+					return fmt.Errorf("unsupported dedupe key %q, must be one of id, crosspost or url", key)
+				}
+			}
+		}
+		if target.Output.Schedule != nil {
+			if err := validateSchedule(target.Output.Schedule); err != nil {
+				return fmt.Errorf("output schedule of target %s: %w", target.Name, err)
+			}
+		}
+		if target.Delivery != nil {
+			switch target.Delivery.Mode {
+			case "", DeliveryImmediate, DeliveryDigest:
+			default:
+				return fmt.Errorf("unsupported delivery mode %q, must be one of immediate or digest", target.Delivery.Mode)
+			}
+		}
+		if target.Content != nil {
+			for _, filter := range []ContentFilter{target.Content.Stickied, target.Content.NSFW, target.Content.Spoiler, target.Content.Crossposts} {
+				switch filter {
+				case "", ContentInclude, ContentExclude, ContentOnly:
+				default:
+					return fmt.Errorf("unsupported content option %q, must be one of include, exclude or only", filter)
+				}
+			}
+			if target.Content.BlurNSFW && target.Content.NSFW == ContentExclude {
+				return errors.New("blur_nsfw can't be combined with nsfw: exclude, NSFW posts are never sent")
+			}
+		}
+		if err := validateOutput(target.Output); err != nil {
+			return err
+		}
+		if target.Output.Type == OutputTypeWebhook && target.Output.Webhook != nil && receivesEmbedsOnly(target) {
+			if body, _ := target.Output.Webhook.BodyTemplate(); body != nil && webhook.UsesPost(body) {
+				return fmt.Errorf("webhook body template of target %s uses .Post, which digests and posts held during quiet hours don't carry, use .Embed instead", target.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// receivesEmbedsOnly reports whether posts may reach the output of the target without the full post,
+// as digests and posts held during quiet hours only carry the embed
+func receivesEmbedsOnly(target Target) bool {
+	if target.Delivery != nil && target.Delivery.Mode == DeliveryDigest {
+		return true
+	}
+	schedule := target.Output.Schedule
+	return schedule != nil && len(schedule.QuietHours) > 0 && schedule.DuringQuiet != QuietDrop
+}
+
+func validateOutput(output OutputConfig) error {
+	switch output.Type {
+	case OutputTypeTelegram:
+		if output.Telegram == nil || output.Telegram.BotToken == "" || output.Telegram.ChatID == "" {
+			return errors.New("telegram output requires a telegram block with bot_token and chat_id")
+		}
+		switch output.Telegram.ParseMode {
+		case "", TelegramParseModeHTML, TelegramParseModeMarkdownV2:
+		default:
+			return fmt.Errorf("unsupported telegram parse_mode %q, must be one of HTML or MarkdownV2", output.Telegram.ParseMode)
+		}
+	case OutputTypeMatrix:
+		if output.Matrix == nil || output.Matrix.HomeserverURL == "" || output.Matrix.AccessToken == "" || output.Matrix.RoomID == "" {
+			return errors.New("matrix output requires a matrix block with homeserver_url, access_token and room_id")
+		}
+		if !strings.HasPrefix(output.Matrix.RoomID, "!") {
+			return fmt.Errorf("matrix room_id %q must be the internal room id starting with '!', not a room alias", output.Matrix.RoomID)
+		}
+	case OutputTypeEmail:
+		if output.Email == nil || output.Email.Host == "" || output.Email.From == "" || len(output.Email.To) == 0 {
+			return errors.New("email output requires an email block with host, from and to")
+		}
+		switch output.Email.Security {
+		case "", EmailSecurityStartTLS, EmailSecurityTLS, EmailSecurityNone:
+		default:
+			return fmt.Errorf("unsupported email security %q, must be one of starttls, tls or none", output.Email.Security)
+		}
+		// Go only sends credentials over an unencrypted connection to the local machine
+		if output.Email.Security == EmailSecurityNone && output.Email.Username != "" && !isLocalhost(output.Email.Host) {
+			return fmt.Errorf("email security none can't be used with a username for %s, credentials are only sent unencrypted to localhost, use starttls or tls", output.Email.Host)
+		}
+		for _, address := range append([]string{output.Email.From}, output.Email.To...) {
+			if _, err := mail.ParseAddress(address); err != nil {
+				return fmt.Errorf("invalid email address %q: %w", address, err)
+			}
+		}
+	case OutputTypeNtfy:
+		if output.Ntfy == nil || output.Ntfy.Topic == "" {
+			return errors.New("ntfy output requires a ntfy block with topic")
+		}
+		if output.Ntfy.Priority < 0 || output.Ntfy.Priority > 5 {
+			return fmt.Errorf("invalid ntfy priority %d, must be between 1 and 5, or 0 for the server default", output.Ntfy.Priority)
+		}
+	case OutputTypeGotify:
+		if output.Gotify == nil || output.Gotify.ServerURL == "" || output.Gotify.AppToken == "" {
+			return errors.New("gotify output requires a gotify block with server_url and app_token")
+		}
+		if priority := output.Gotify.Priority; priority != nil && (*priority < 0 || *priority > 10) {
+			return fmt.Errorf("invalid gotify priority %d, must be between 0 and 10", *priority)
+		}
+	case OutputTypePushover:
+		if output.Pushover == nil || output.Pushover.AppToken == "" || output.Pushover.UserKey == "" {
+			return errors.New("pushover output requires a pushover block with app_token and user_key")
+		}
+		// Emergency priority 2 needs acknowledgement handling, which is not supported
+		if output.Pushover.Priority < -2 || output.Pushover.Priority > 1 {
+			return fmt.Errorf("invalid pushover priority %d, must be between -2 and 1", output.Pushover.Priority)
+		}
+	case OutputTypeFile, OutputTypeStdout:
+		if output.Type == OutputTypeFile && (output.File == nil || output.File.Path == "") {
+			return errors.New("file output requires a file block with path")
+		}
+		if output.File == nil {
+			return nil
+		}
+		switch output.File.Format {
+		case "", FileFormatJSONL, FileFormatCSV, FileFormatMarkdown:
+		default:
+			return fmt.Errorf("unsupported file format %q, must be one of jsonl, csv or markdown", output.File.Format)
+		}
+		switch output.File.Rotate {
+		case "", FileRotateNone, FileRotateSize, FileRotateDaily:
+		default:
+			return fmt.Errorf("unsupported file rotate %q, must be one of none, size or daily", output.File.Rotate)
+		}
+		if output.File.MaxSize < 0 {
+			return fmt.Errorf("invalid file max_size %d", output.File.MaxSize)
+		}
+	case OutputTypeIRC:
+		if output.IRC == nil || output.IRC.Server == "" || output.IRC.Nick == "" || len(output.IRC.Channels) == 0 {
+			return errors.New("irc output requires an irc block with server, nick and channels")
+		}
+		if strings.ContainsAny(output.IRC.Nick, " \r\n") {
+			return fmt.Errorf("invalid irc nick %q", output.IRC.Nick)
+		}
+		for _, channel := range output.IRC.Channels {
+			if !strings.HasPrefix(channel, "#") && !strings.HasPrefix(channel, "&") {
+				return fmt.Errorf("invalid irc channel %q, must start with # or &", channel)
+			}
+		}
+		if (output.IRC.SASLUsername == "") != (output.IRC.SASLPassword == "") {
+			return errors.New("irc sasl_username and sasl_password must be set together")
+		}
+		if output.IRC.FloodBurst < 0 || output.IRC.FloodDelay < 0 {
+			return errors.New("irc flood_burst and flood_delay must not be negative")
+		}
+	case OutputTypeCommand:
+		if output.Command == nil || len(output.Command.Run) == 0 || output.Command.Run[0] == "" {
+			return errors.New("command output requires a command block with run")
+		}
+		if output.Command.Timeout < 0 || output.Command.Concurrency < 0 {
+			return errors.New("command timeout and concurrency must not be negative")
+		}
+	case OutputTypeWebhook:
+		if output.WebhookURL == "" {
+			return errors.New("webhook output requires a webhook_url")
+		}
+		if output.Webhook == nil {
+			return nil
+		}
+		if output.Webhook.Method != "" && !methodPattern.MatchString(output.Webhook.Method) {
+			return fmt.Errorf("invalid webhook method %q", output.Webhook.Method)
+		}
+		for _, status := range output.Webhook.ExpectedStatus {
+			if status < 100 || status > 599 {
+				return fmt.Errorf("invalid webhook expected_status %d", status)
+			}
+		}
+		body, err := webhook.ParseTemplate(output.Webhook.Body)
+		if err != nil {
+			return fmt.Errorf("invalid webhook body template: %w", err)
+		}
+		output.Webhook.template = body
+	default:
+		if output.WebhookURL == "" {
 			return errors.New("output block is not correctly configured")
 		}
 	}
 	return nil
 }
 
+func validateOptions(options *Options) error {
+	if options == nil {
+		return nil
+	}
+	if options.Schedule != "" {
+		if options.Adaptive != nil {
+			return errors.New("schedule and adaptive polling can't be combined")
+		}
+		if _, err := cron.Parse(options.Schedule); err != nil {
+			return err
+		}
+	}
+	// Zero intervals are left to the defaults
+	if options.Interval < 0 || (options.Interval > 0 && options.Interval < MinimumInterval) {
+		return fmt.Errorf("interval must be at least %d seconds", MinimumInterval)
+	}
+	if options.Adaptive != nil {
+		if options.Adaptive.MinInterval < 0 || (options.Adaptive.MinInterval > 0 && options.Adaptive.MinInterval < MinimumInterval) {
+			return fmt.Errorf("adaptive min_interval must be at least %d seconds", MinimumInterval)
+		}
+		if options.Adaptive.MaxInterval < 0 {
+			return errors.New("adaptive max_interval can't be negative")
+		}
+		if options.Adaptive.MaxInterval != 0 && options.Adaptive.MinInterval > options.Adaptive.MaxInterval {
+			return errors.New("adaptive min_interval can't be larger than max_interval")
+		}
+	}
+	return nil
+}
+
+func validateSchedule(schedule *OutputSchedule) error {
+	if _, err := time.LoadLocation(schedule.Timezone); err != nil {
+		return fmt.Errorf("invalid timezone %q: %w", schedule.Timezone, err)
+	}
+	switch schedule.DuringQuiet {
+	case "", QuietHold, QuietDrop:
+	default:
+		return fmt.Errorf("unsupported during_quiet %q, must be one of hold or drop", schedule.DuringQuiet)
+	}
+	for _, quiet := range schedule.QuietHours {
+		for _, clock := range []string{quiet.Start, quiet.End} {
+			if _, err := time.Parse("15:04", clock); err != nil {
+				return fmt.Errorf("invalid time %q, must be formatted as HH:MM", clock)
+			}
+		}
+		for _, day := range quiet.Days {
+			if _, ok := Weekdays[strings.ToLower(day)]; !ok {
+				return fmt.Errorf("invalid day %q, must be one of mon, tue, wed, thu, fri, sat or sun", day)
+			}
+		}
+	}
+	return nil
+}
+
+// isLocalhost reports whether the host is the local machine the way net/smtp decides it
+func isLocalhost(host string) bool {
+	return host == "localhost" || host == "127.0.0.1" || host == "::1"
+}
+
+func validateFilter(filter *Filter) error {
+	if filter.Field == "" {
+		return errors.New("filter requires a field")
+	}
+	if filter.Contains == "" && filter.StartsWith == "" && filter.Equals == "" && filter.Matches == "" && filter.Min == nil && filter.Max == nil {
+		return fmt.Errorf("filter on %q requires at least one of contains, starts_with, equals, matches, min or max", filter.Field)
+	}
+	if filter.Matches != "" {
+		pattern, err := compilePattern(filter.Matches)
+		if err != nil {
+			return fmt.Errorf("invalid filter pattern %q: %w", filter.Matches, err)
+		}
+		filter.pattern = pattern
+	}
+	return nil
+}
+
+func validateMonitor(monitor Monitor) error {
+	sources := 0
+	if monitor.Subreddit != "" {
+		sources++
+	}
+	if monitor.User != nil {
+		sources++
+	}
+	if monitor.Multireddit != nil {
+		sources++
+	}
+	if monitor.Inbox != "" {
+		sources++
+	}
+	if sources > 1 {
+		return errors.New("monitor block can only contain one of subreddit, user, multireddit or inbox")
+	}
+	if monitor.MarkRead && monitor.Inbox == "" {
+		return errors.New("mark_read can only be used with inbox feeds")
+	}
+	if monitor.Moderation != "" && monitor.Subreddit == "" && sources > 0 {
+		return errors.New("moderation feeds can only be used with a subreddit")
+	}
+	switch {
+	case monitor.User != nil:
+		if monitor.User.Name == "" {
+			return errors.New("monitor user block requires a name")
+		}
+		switch monitor.User.Feed {
+		case "", UserFeedSubmitted, UserFeedComments, UserFeedOverview:
+		default:
+			return fmt.Errorf("unsupported user feed %q, must be one of submitted, comments or overview", monitor.User.Feed)
+		}
+		// User feeds only support a subset of the listing sorts
+		switch monitor.Sorting {
+		case "", SortingHot, SortingNew, SortingTop, SortingControversial:
+		default:
+			return fmt.Errorf("unsupported sorting %q for user feeds, must be one of hot, new, top or controversial", monitor.Sorting)
+		}
+	case monitor.Multireddit != nil:
+		if monitor.Multireddit.User == "" || monitor.Multireddit.Name == "" {
+			return errors.New("monitor multireddit block requires a user and a name")
+		}
+		if monitor.Sorting == "" {
+			return errors.New("monitor block is not correctly configured")
+		}
+	case monitor.Inbox != "":
+		switch monitor.Inbox {
+		case InboxUnread, InboxMentions:
+		default:
+			return fmt.Errorf("unsupported inbox feed %q, must be one of unread or mentions", monitor.Inbox)
+		}
+	case monitor.Moderation != "":
+		if monitor.Subreddit == "" {
+			return errors.New("moderation feeds require a subreddit")
+		}
+		switch monitor.Moderation {
+		case ModerationModqueue, ModerationReports, ModerationSpam, ModerationLog, ModerationModmail:
+		default:
+			return fmt.Errorf("unsupported moderation feed %q, must be one of modqueue, reports, spam, log or modmail", monitor.Moderation)
+		}
+	default:
+		if monitor.Subreddit == "" || monitor.Sorting == "" {
+			return errors.New("monitor block is not correctly configured")
+		}
+	}
+
+	if monitor.Sorting != "" && !isValidSorting(monitor.Sorting) {
+		return fmt.Errorf("unsupported sorting %q, must be one of hot, new, top, controversial, rising or best", monitor.Sorting)
+	}
+	if monitor.TimeFilter != "" {
+		if monitor.Sorting != SortingTop && monitor.Sorting != SortingControversial {
+			return fmt.Errorf("time_filter can only be used with top or controversial sorting, got %q", monitor.Sorting)
+		}
+		if !isValidTimeFilter(monitor.TimeFilter) {
+			return fmt.Errorf("unsupported time_filter %q, must be one of hour, day, week, month, year or all", monitor.TimeFilter)
+		}
+	}
+	return nil
+}
+
+func isValidSorting(sorting string) bool {
+	switch sorting {
+	case SortingHot, SortingNew, SortingTop, SortingControversial, SortingRising, SortingBest:
+		return true
+	}
+	return false
+}
+
+func isValidTimeFilter(timeFilter string) bool {
+	switch timeFilter {
+	case TimeFilterHour, TimeFilterDay, TimeFilterWeek, TimeFilterMonth, TimeFilterYear, TimeFilterAll:
+		return true
+	}
+	return false
+}
+
+func setMonitorDefaults(monitor *Monitor) {
+	// Accept "r/a+b" as well as "a+b"
+	monitor.Subreddit = strings.TrimPrefix(strings.TrimPrefix(monitor.Subreddit, "/"), "r/")
+	if monitor.Multireddit != nil {
+		monitor.Multireddit.User = strings.TrimPrefix(strings.TrimPrefix(monitor.Multireddit.User, "/"), "u/")
+	}
+	if monitor.User != nil {
+		monitor.User.Name = strings.TrimPrefix(strings.TrimPrefix(monitor.User.Name, "/"), "u/")
+		if monitor.User.Feed == "" {
+			monitor.User.Feed = UserFeedSubmitted
+		}
+		if monitor.Sorting == "" {
+			monitor.Sorting = SortingNew
+		}
+	}
+}
+
 func setGlobalDefaults(config *Config) {
 	if config.Options == nil {
 		config.Options = &Options{
@@ -162,6 +1108,10 @@ func setGlobalDefaults(config *Config) {
 			RetryInterval: DefaultRetryInterval,
 		}
 	}
+	// An options block without an interval would poll without any delay
+	if config.Options.Interval == 0 {
+		config.Options.Interval = DefaultInterval
+	}
 }
 
 
@@ -170,6 +1120,150 @@ func setTargetDefaults(config *Config, target *Target) {
 	if target.Options == nil {
 		target.Options = &Options{}
 	}
+	if target.TrackUpdates != nil && target.TrackUpdates.Window == 0 {
+		target.TrackUpdates.Window = DefaultUpdateWindow
+	}
+	if target.Trending != nil && target.Trending.Window == 0 {
+		target.Trending.Window = DefaultTrendWindow
+	}
+	if target.Dedupe != nil {
+		if target.Dedupe.Scope == "" {
+			target.Dedupe.Scope = DedupeScopeGlobal
+		}
+		if len(target.Dedupe.Keys) == 0 {
+			target.Dedupe.Keys = []DedupeKey{DedupeKeyID, DedupeKeyCrosspost, DedupeKeyURL}
+		}
+	}
+	if telegram := target.Output.Telegram; telegram != nil {
+		if telegram.ParseMode == "" {
+			telegram.ParseMode = TelegramParseModeHTML
+		}
+		if telegram.APIURL == "" {
+			telegram.APIURL = DefaultTelegramAPIURL
+		}
+		telegram.APIURL = strings.TrimSuffix(telegram.APIURL, "/")
+	}
+	if target.Output.Type == OutputTypeWebhook {
+		if target.Output.Webhook == nil {
+			target.Output.Webhook = &WebhookOutput{}
+		}
+		target.Output.Webhook.Method = strings.ToUpper(target.Output.Webhook.Method)
+		if target.Output.Webhook.Method == "" {
+			target.Output.Webhook.Method = http.MethodPost
+		}
+	}
+	if email := target.Output.Email; email != nil {
+		if email.Security == "" {
+			email.Security = EmailSecurityStartTLS
+		}
+		if email.Port == 0 {
+			switch email.Security {
+			case EmailSecurityTLS:
+				email.Port = 465
+			case EmailSecurityNone:
+				email.Port = 25
+			default:
+				email.Port = 587
+			}
+		}
+	}
+	if target.Output.Type == OutputTypeFile || target.Output.Type == OutputTypeStdout {
+		if target.Output.File == nil {
+			target.Output.File = &FileOutput{}
+		}
+		if target.Output.File.Format == "" {
+			target.Output.File.Format = FileFormatJSONL
+		}
+		if target.Output.File.Rotate == "" {
+			target.Output.File.Rotate = FileRotateNone
+		}
+		if target.Output.File.MaxSize == 0 {
+			target.Output.File.MaxSize = DefaultFileMaxSize
+		}
+	}
+	if irc := target.Output.IRC; irc != nil {
+		if _, _, err := net.SplitHostPort(irc.Server); err != nil {
+			port := "6667"
+			if irc.TLS {
+				port = "6697"
+			}
+			irc.Server = net.JoinHostPort(irc.Server, port)
+		}
+		if irc.Username == "" {
+			irc.Username = irc.Nick
+		}
+		if irc.RealName == "" {
+			irc.RealName = "xenigo"
+		}
+		if irc.FloodBurst == 0 {
+			irc.FloodBurst = DefaultIRCFloodBurst
+		}
+		if irc.FloodDelay == 0 {
+			irc.FloodDelay = DefaultIRCFloodDelay
+		}
+	}
+	if command := target.Output.Command; command != nil {
+		if command.Timeout == 0 {
+			command.Timeout = DefaultCommandTimeout
+		}
+		if command.Concurrency == 0 {
+			command.Concurrency = DefaultCommandConcurrency
+		}
+	}
+	if ntfy := target.Output.Ntfy; ntfy != nil {
+		if ntfy.ServerURL == "" {
+			ntfy.ServerURL = DefaultNtfyServerURL
+		}
+		ntfy.ServerURL = strings.TrimSuffix(ntfy.ServerURL, "/")
+	}
+	if gotify := target.Output.Gotify; gotify != nil {
+		gotify.ServerURL = strings.TrimSuffix(gotify.ServerURL, "/")
+	}
+	if pushover := target.Output.Pushover; pushover != nil {
+		if pushover.APIURL == "" {
+			pushover.APIURL = DefaultPushoverAPIURL
+		}
+		pushover.APIURL = strings.TrimSuffix(pushover.APIURL, "/")
+	}
+	if matrix := target.Output.Matrix; matrix != nil {
+		matrix.HomeserverURL = strings.TrimSuffix(matrix.HomeserverURL, "/")
+	}
+	if target.Output.Schedule != nil && target.Output.Schedule.DuringQuiet == "" {
+		target.Output.Schedule.DuringQuiet = QuietHold
+	}
+	if target.Delivery == nil {
+		target.Delivery = &Delivery{}
+	}
+	if target.Delivery.Mode == "" {
+		target.Delivery.Mode = DeliveryImmediate
+	}
+	if target.Delivery.Window == 0 {
+		target.Delivery.Window = DefaultDigestWindow
+	}
+	if target.Delivery.MaxItems == 0 {
+		target.Delivery.MaxItems = DefaultDigestItems
+	}
+	for i := range target.Mentions {
+		if target.Mentions[i].Field == "" {
+			target.Mentions[i].Field = "title"
+		}
+	}
+	if target.Content == nil {
+		target.Content = &Content{}
+	}
+	// Pinned posts are mostly mod announcements, so they are excluded unless configured otherwise
+	if target.Content.Stickied == "" {
+		target.Content.Stickied = ContentExclude
+	}
+	if target.Content.NSFW == "" {
+		target.Content.NSFW = ContentInclude
+	}
+	if target.Content.Spoiler == "" {
+		target.Content.Spoiler = ContentInclude
+	}
+	if target.Content.Crossposts == "" {
+		target.Content.Crossposts = ContentInclude
+	}
 	if config.Options != nil {
 		if target.Options.Interval == 0 {
 			target.Options.Interval = config.Options.Interval
@@ -183,6 +1277,22 @@ func setTargetDefaults(config *Config, target *Target) {
 		if target.Options.RetryInterval == 0 {
 			target.Options.RetryInterval = config.Options.RetryInterval
 		}
+		// A target with its own schedule or adaptive polling doesn't inherit the other one
+		if target.Options.Schedule == "" && target.Options.Adaptive == nil {
+			target.Options.Schedule = config.Options.Schedule
+			target.Options.Adaptive = config.Options.Adaptive
+		}
+	}
+	if adaptive := target.Options.Adaptive; adaptive != nil {
+		if adaptive.MinInterval == 0 {
+			adaptive.MinInterval = DefaultMinInterval
+		}
+		if adaptive.MaxInterval == 0 {
+			adaptive.MaxInterval = DefaultMaxInterval
+		}
+		if adaptive.MinInterval > adaptive.MaxInterval {
+			adaptive.MinInterval = adaptive.MaxInterval
+		}
 	}
 }
 
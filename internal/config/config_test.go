@@ -101,3 +101,286 @@ func loadConfigFromString(data string) (*Config, error) {
 
 	return &config, nil
 }
+
+func TestValidateMonitor(t *testing.T) {
+	tests := []struct {
+		name        string
+		monitor     Monitor
+		expectError bool
+	}{
+		{
+			name:    "Subreddit monitor",
+			monitor: Monitor{Subreddit: "cats", Sorting: "hot"},
+		},
+		{
+			name:    "User monitor without sorting",
+			monitor: Monitor{User: &UserMonitor{Name: "spez", Feed: UserFeedComments}},
+		},
+		{
+			name:        "User monitor without name",
+			monitor:     Monitor{User: &UserMonitor{Feed: UserFeedOverview}},
+			expectError: true,
+		},
+		{
+			name:        "User monitor with unknown feed",
+			monitor:     Monitor{User: &UserMonitor{Name: "spez", Feed: "upvoted"}},
+			expectError: true,
+		},
+		{
+			name:    "Combined subreddit monitor",
+			monitor: Monitor{Subreddit: "cats+dogs", Sorting: "new"},
+		},
+		{
+			name:    "Multireddit monitor",
+			monitor: Monitor{Multireddit: &MultiredditMonitor{User: "spez", Name: "pets"}, Sorting: "new"},
+		},
+		{
+			name:        "Multireddit monitor without name",
+			monitor:     Monitor{Multireddit: &MultiredditMonitor{User: "spez"}, Sorting: "new"},
+			expectError: true,
+		},
+		{
+			name:        "Unknown sorting",
+			monitor:     Monitor{Subreddit: "cats", Sorting: "newest"},
+			expectError: true,
+		},
+		{
+			name:        "Rising sorting for user feed",
+			monitor:     Monitor{User: &UserMonitor{Name: "spez"}, Sorting: "rising"},
+			expectError: true,
+		},
+		{
+			name:    "Top sorting with time filter",
+			monitor: Monitor{Subreddit: "cats", Sorting: "top", TimeFilter: "week"},
+		},
+		{
+			name:        "Time filter without top or controversial sorting",
+			monitor:     Monitor{Subreddit: "cats", Sorting: "hot", TimeFilter: "week"},
+			expectError: true,
+		},
+		{
+			name:        "Unknown time filter",
+			monitor:     Monitor{Subreddit: "cats", Sorting: "controversial", TimeFilter: "decade"},
+			expectError: true,
+		},
+		{
+			name:    "Moderation feed without sorting",
+			monitor: Monitor{Subreddit: "cats", Moderation: ModerationModqueue},
+		},
+		{
+			name:        "Unknown moderation feed",
+			monitor:     Monitor{Subreddit: "cats", Moderation: "banned"},
+			expectError: true,
+		},
+		{
+			name:        "Moderation feed for user",
+			monitor:     Monitor{User: &UserMonitor{Name: "spez"}, Moderation: ModerationLog},
+			expectError: true,
+		},
+		{
+			name:    "Inbox monitor marking messages read",
+			monitor: Monitor{Inbox: InboxMentions, MarkRead: true},
+		},
+		{
+			name:        "Mark read without inbox",
+			monitor:     Monitor{Subreddit: "cats", Sorting: "new", MarkRead: true},
+			expectError: true,
+		},
+		{
+			name:        "Subreddit and user monitor",
+			monitor:     Monitor{Subreddit: "cats", Sorting: "hot", User: &UserMonitor{Name: "spez"}},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateMonitor(tt.monitor)
+			if (err != nil) != tt.expectError {
+				t.Errorf("validateMonitor() error = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestSetTargetNames(t *testing.T) {
+	config := &Config{Targets: []Target{
+		{Monitor: Monitor{Subreddit: "cats"}},
+		{Name: "cats-2", Monitor: Monitor{Subreddit: "dogs"}},
+		{Monitor: Monitor{Subreddit: "cats"}},
+		{Monitor: Monitor{Inbox: InboxMentions}},
+	}}
+	setTargetNames(config)
+
+	expected := []string{"cats", "cats-2", "cats-3", "inbox/mentions"}
+	for i, target := range config.Targets {
+		if target.Name != expected[i] {
+			t.Errorf("target %d named %q, expected %q", i, target.Name, expected[i])
+		}
+	}
+}
+
+func TestValidateConfigDuplicateNames(t *testing.T) {
+	target := Target{Name: "Cats", Monitor: Monitor{Subreddit: "cats", Sorting: "new"}}
+	config := &Config{UserAgent: "xenigo", Targets: []Target{target, target}}
+	if err := validateConfig(config); err == nil {
+		t.Error("validateConfig() accepted two targets with the same name")
+	}
+}
+
+func TestValidateOptionsInterval(t *testing.T) {
+	tests := []struct {
+		name        string
+		options     Options
+		expectError bool
+	}{
+		{name: "Interval left to the default", options: Options{}},
+		{name: "Interval at the minimum", options: Options{Interval: MinimumInterval}},
+		{name: "Interval below the minimum", options: Options{Interval: 1}, expectError: true},
+		{name: "Negative interval", options: Options{Interval: -60}, expectError: true},
+		{name: "Adaptive minimum below the minimum", options: Options{Adaptive: &Adaptive{MinInterval: 2}}, expectError: true},
+		{name: "Adaptive minimum above the maximum", options: Options{Adaptive: &Adaptive{MinInterval: 60, MaxInterval: 30}}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateOptions(&tt.options)
+			if (err != nil) != tt.expectError {
+				t.Errorf("validateOptions() error = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestSetGlobalDefaultsInterval(t *testing.T) {
+	config := &Config{Options: &Options{Limit: 5}}
+	setGlobalDefaults(config)
+	if config.Options.Interval != DefaultInterval {
+		t.Errorf("options without interval got interval %d, expected %d", config.Options.Interval, DefaultInterval)
+	}
+}
+
+func TestValidateConfigWebhookTemplateWithoutPost(t *testing.T) {
+	target := Target{
+		Name:    "Listings",
+		Monitor: Monitor{Subreddit: "hardwareswap", Sorting: "new"},
+		Output: OutputConfig{
+			Type:       OutputTypeWebhook,
+			WebhookURL: "https://example.org/hook",
+			Webhook:    &WebhookOutput{Body: `{"title": {{ json .Post.Title }}}`},
+		},
+	}
+	config := &Config{UserAgent: "xenigo", Targets: []Target{target}}
+	if err := validateConfig(config); err != nil {
+		t.Fatalf("validateConfig() error = %v for immediate delivery", err)
+	}
+
+	config.Targets[0].Delivery = &Delivery{Mode: DeliveryDigest}
+	if err := validateConfig(config); err == nil {
+		t.Error("validateConfig() accepted a template using .Post for digests")
+	}
+
+	config.Targets[0].Output.Webhook.Body = `{"title": {{ json .Embed.Title }}}`
+	if err := validateConfig(config); err != nil {
+		t.Errorf("validateConfig() error = %v for a template using .Embed", err)
+	}
+}
+
+func TestValidateConfigBlurExcludedNSFW(t *testing.T) {
+	target := Target{
+		Name:    "Cats",
+		Monitor: Monitor{Subreddit: "cats", Sorting: "new"},
+		Output:  OutputConfig{Type: OutputTypeDiscord, WebhookURL: "https://discord.com/api/webhooks/1/token"},
+		Content: &Content{NSFW: ContentInclude, BlurNSFW: true},
+	}
+	config := &Config{UserAgent: "xenigo", Targets: []Target{target}}
+	if err := validateConfig(config); err != nil {
+		t.Fatalf("validateConfig() error = %v for blurred NSFW posts", err)
+	}
+
+	config.Targets[0].Content.NSFW = ContentExclude
+	if err := validateConfig(config); err == nil {
+		t.Error("validateConfig() accepted blur_nsfw for excluded NSFW posts")
+	}
+}
+
+func TestValidateFilter(t *testing.T) {
+	limit := 500.0
+	tests := []struct {
+		name        string
+		filter      Filter
+		expectError bool
+	}{
+		{name: "Contains", filter: Filter{Field: "title", Contains: "4090"}},
+		{name: "Price range", filter: Filter{Field: "price", Max: &limit}},
+		{name: "Pattern", filter: Filter{Field: "flair", Matches: "^(selling|trading)$"}},
+		{name: "No field", filter: Filter{Contains: "4090"}, expectError: true},
+		{name: "No condition", filter: Filter{Field: "title"}, expectError: true},
+		{name: "Invalid pattern", filter: Filter{Field: "title", Matches: "(4090"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateFilter(&tt.filter)
+			if (err != nil) != tt.expectError {
+				t.Errorf("validateFilter() error = %v, expectError %v", err, tt.expectError)
+			}
+			if err == nil && tt.filter.Matches != "" && tt.filter.pattern == nil {
+				t.Error("validateFilter() did not keep the compiled pattern")
+			}
+		})
+	}
+}
+
+func TestValidateOutputWebhook(t *testing.T) {
+	tests := []struct {
+		name        string
+		webhook     WebhookOutput
+		expectError bool
+	}{
+		{name: "Default method", webhook: WebhookOutput{}},
+		{name: "Lowercase method", webhook: WebhookOutput{Method: "put"}},
+		{name: "Custom method", webhook: WebhookOutput{Method: "PROPFIND"}},
+		{name: "Method with a space", webhook: WebhookOutput{Method: "POST NOW"}, expectError: true},
+		{name: "Method with a newline", webhook: WebhookOutput{Method: "POST\n"}, expectError: true},
+		{name: "Body template", webhook: WebhookOutput{Body: `{"title": {{ json .Embed.Title }}}`}},
+		{name: "Invalid body template", webhook: WebhookOutput{Body: `{"title": {{ json .Embed.Title }`}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := OutputConfig{Type: OutputTypeWebhook, WebhookURL: "https://example.org/hook", Webhook: &tt.webhook}
+			err := validateOutput(output)
+			if (err != nil) != tt.expectError {
+				t.Errorf("validateOutput() error = %v, expectError %v", err, tt.expectError)
+			}
+			if err == nil && tt.webhook.Body != "" && tt.webhook.template == nil {
+				t.Error("validateOutput() did not keep the parsed body template")
+			}
+		})
+	}
+}
+
+func TestValidateOutputEmailSecurity(t *testing.T) {
+	tests := []struct {
+		name        string
+		email       EmailOutput
+		expectError bool
+	}{
+		{name: "Relay without credentials", email: EmailOutput{Host: "mail.example.org", Security: EmailSecurityNone}},
+		{name: "Local relay with credentials", email: EmailOutput{Host: "localhost", Security: EmailSecurityNone, Username: "xenigo"}},
+		{name: "STARTTLS with credentials", email: EmailOutput{Host: "mail.example.org", Username: "xenigo"}},
+		{name: "Unencrypted credentials", email: EmailOutput{Host: "mail.example.org", Security: EmailSecurityNone, Username: "xenigo"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.email.From = "xenigo@example.org"
+			tt.email.To = []string{"me@example.org"}
+			err := validateOutput(OutputConfig{Type: OutputTypeEmail, Email: &tt.email})
+			if (err != nil) != tt.expectError {
+				t.Errorf("validateOutput() error = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+}
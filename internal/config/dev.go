@@ -81,15 +81,67 @@ func setDeveloperFlagsDefaults(config *Config) {
 	}
 }
 
+// obfuscateSecrets blanks the secrets of a shallow copy of the config, the nested
+// values holding secrets are copied first so the original config is left untouched
 func obfuscateSecrets(config *Config) {
 	if config.OAuth != nil {
-		config.OAuth.ClientID = "********"
-		config.OAuth.ClientSecret = "********"
-		config.OAuth.Username = ""
-		config.OAuth.Password = "********"
-	}
+		oauth := *config.OAuth
+		oauth.ClientID = "********"
+		oauth.ClientSecret = "********"
+		oauth.Username = ""
+		oauth.Password = "********"
+		config.OAuth = &oauth
+	}
+	config.Targets = append([]Target(nil), config.Targets...)
 	for i := range config.Targets {
-		config.Targets[i].Output.WebhookURL = "********"
+		output := &config.Targets[i].Output
+		output.WebhookURL = "********"
+		if output.Telegram != nil {
+			telegram := *output.Telegram
+			telegram.BotToken = "********"
+			output.Telegram = &telegram
+		}
+		if output.Webhook != nil && len(output.Webhook.Headers) > 0 {
+			webhook := *output.Webhook
+			webhook.Headers = make(map[string]string)
+			for name := range output.Webhook.Headers {
+				webhook.Headers[name] = "********"
+			}
+			output.Webhook = &webhook
+		}
+		if output.Email != nil {
+			email := *output.Email
+			email.Password = "********"
+			output.Email = &email
+		}
+		if output.Ntfy != nil {
+			ntfy := *output.Ntfy
+			ntfy.Token = "********"
+			output.Ntfy = &ntfy
+		}
+		if output.Gotify != nil {
+			gotify := *output.Gotify
+			gotify.AppToken = "********"
+			output.Gotify = &gotify
+		}
+		if output.Pushover != nil {
+			pushover := *output.Pushover
+			pushover.AppToken = "********"
+			pushover.UserKey = "********"
+			output.Pushover = &pushover
+		}
+		if output.IRC != nil {
+			irc := *output.IRC
+			irc.Password = "********"
+			irc.SASLPassword = "********"
+			irc.NickServPassword = "********"
+			output.IRC = &irc
+		}
+		if output.Matrix != nil {
+			matrix := *output.Matrix
+			matrix.AccessToken = "********"
+			output.Matrix = &matrix
+		}
 	}
 }
 
@@ -0,0 +1,45 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	from := time.Date(2024, 6, 7, 17, 58, 30, 0, time.UTC) // Friday
+
+	tests := []struct {
+		expression string
+		expected   time.Time
+	}{
+		{"* * * * *", time.Date(2024, 6, 7, 17, 59, 0, 0, time.UTC)},
+		{"*/5 * * * *", time.Date(2024, 6, 7, 18, 0, 0, 0, time.UTC)},
+		{"*/10 8-17 * * 1-5", time.Date(2024, 6, 10, 8, 0, 0, 0, time.UTC)},
+		{"0 9 * * 0,6", time.Date(2024, 6, 8, 9, 0, 0, 0, time.UTC)},
+		{"30 12 1 * *", time.Date(2024, 7, 1, 12, 30, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 12 15 * 7", time.Date(2024, 6, 9, 12, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			schedule, err := Parse(tt.expression)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if next := schedule.Next(from); !next.Equal(tt.expected) {
+				t.Errorf("Next() = %v, expected %v", next, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, expression := range []string{"* * * *", "60 * * * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		t.Run(expression, func(t *testing.T) {
+			if _, err := Parse(expression); err == nil {
+				t.Errorf("Parse() expected an error")
+			}
+		})
+	}
+}
@@ -6,16 +6,29 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
+	"strings"
 	"xenigo/internal/output"
 )
 
+const maxEmbedsPerMessage = 10
+
 type DiscordWebhook struct {
-    Embeds []DiscordEmbed `json:"embeds"`
+    Content         string           `json:"content,omitempty"`
+    Embeds          []DiscordEmbed   `json:"embeds"`
+    AllowedMentions *AllowedMentions `json:"allowed_mentions,omitempty"`
+}
+
+// AllowedMentions restricts the pings of a message to the listed roles and users
+type AllowedMentions struct {
+    Parse []string `json:"parse"`
+    Roles []string `json:"roles,omitempty"`
+    Users []string `json:"users,omitempty"`
 }
 
 type DiscordEmbed struct {
     Title       string       `json:"title"`
-    Description string       `json:"description"`
+    Description string       `json:"description,omitempty"`
     URL         string       `json:"url,omitempty"`
     Author      EmbedAuthor  `json:"author,omitempty"`
     Fields      []EmbedField `json:"fields,omitempty"`
@@ -37,33 +50,167 @@ type DiscordSender struct {
 func (d *DiscordSender) SendMessage(embed output.MessageEmbed) error {
     log.Printf("Sending message to Discord: %s", embed.Title) // Log statement
 
-    discordEmbed := DiscordEmbed{
-        Title:       embed.Title,
-        Description: embed.Description,
-        URL:         embed.URL,
-        Author:      EmbedAuthor{Name: embed.Author},
-        Fields:      convertFields(embed.Fields),
+    resp, err := d.execute(http.MethodPost, d.WebhookURL, embed)
+    if err != nil {
+        return err
     }
+    defer resp.Body.Close()
 
-    webhook := DiscordWebhook{Embeds: []DiscordEmbed{discordEmbed}}
-    webhookBody, err := json.Marshal(webhook)
+    if resp.StatusCode != http.StatusNoContent {
+        return fmt.Errorf("received non-204 response code: %d", resp.StatusCode)
+    }
+
+    return nil
+}
+
+// SendTrackedMessage sends the message and waits for Discord to return it, so it can be edited later on.
+func (d *DiscordSender) SendTrackedMessage(embed output.MessageEmbed) (string, error) {
+    log.Printf("Sending tracked message to Discord: %s", embed.Title)
+
+    endpoint, err := d.endpoint("", true)
     if err != nil {
-        return fmt.Errorf("failed to marshal webhook body: %w", err)
+        return "", err
     }
+    resp, err := d.execute(http.MethodPost, endpoint, embed)
+    if err != nil {
+        return "", err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode != http.StatusOK {
+        return "", fmt.Errorf("received non-200 response code: %d", resp.StatusCode)
+    }
+
+    var message struct {
+        ID string `json:"id"`
+    }
+    if err := json.NewDecoder(resp.Body).Decode(&message); err != nil {
+        return "", fmt.Errorf("failed to decode webhook message: %w", err)
+    }
+    return message.ID, nil
+}
 
-    resp, err := http.Post(d.WebhookURL, "application/json", bytes.NewBuffer(webhookBody))
+// EditMessage replaces the embed of a message previously sent through this webhook.
+func (d *DiscordSender) EditMessage(messageID string, embed output.MessageEmbed) error {
+    log.Printf("Editing Discord message %s: %s", messageID, embed.Title)
+
+    endpoint, err := d.endpoint("/messages/"+url.PathEscape(messageID), false)
+    if err != nil {
+        return err
+    }
+    resp, err := d.execute(http.MethodPatch, endpoint, embed)
     if err != nil {
-        return fmt.Errorf("failed to send webhook: %w", err)
+        return err
     }
     defer resp.Body.Close()
 
-    if resp.StatusCode != http.StatusNoContent {
-        return fmt.Errorf("received non-204 response code: %d", resp.StatusCode)
+    if resp.StatusCode != http.StatusOK {
+        return fmt.Errorf("received non-200 response code: %d", resp.StatusCode)
     }
+    return nil
+}
+
+// SendMessages sends the embeds in as few messages as possible, Discord allows up to 10 embeds per message.
+func (d *DiscordSender) SendMessages(embeds []output.MessageEmbed) error {
+    log.Printf("Sending %d embeds to Discord", len(embeds))
+
+    for start := 0; start < len(embeds); start += maxEmbedsPerMessage {
+        end := start + maxEmbedsPerMessage
+        if end > len(embeds) {
+            end = len(embeds)
+        }
 
+        resp, err := d.execute(http.MethodPost, d.WebhookURL, embeds[start:end]...)
+        if err != nil {
+            return err
+        }
+        resp.Body.Close()
+
+        if resp.StatusCode != http.StatusNoContent {
+            return fmt.Errorf("received non-204 response code: %d", resp.StatusCode)
+        }
+    }
     return nil
 }
 
+// endpoint appends the path to the webhook URL and asks Discord to return the message when wait is set.
+// The query of the webhook URL, e.g. thread_id, is kept.
+func (d *DiscordSender) endpoint(path string, wait bool) (string, error) {
+    u, err := url.Parse(d.WebhookURL)
+    if err != nil {
+        return "", fmt.Errorf("invalid webhook URL: %w", err)
+    }
+    u.Path += path
+    if wait {
+        query := u.Query()
+        query.Set("wait", "true")
+        u.RawQuery = query.Encode()
+    }
+    return u.String(), nil
+}
+
+func (d *DiscordSender) execute(method string, requestURL string, embeds ...output.MessageEmbed) (*http.Response, error) {
+    var webhook DiscordWebhook
+    var mentions output.Mentions
+    for _, embed := range embeds {
+        webhook.Embeds = append(webhook.Embeds, convertEmbed(embed))
+        mentions.Add(output.Mentions{DiscordRoles: embed.Mentions.DiscordRoles, DiscordUsers: embed.Mentions.DiscordUsers})
+    }
+    if len(mentions.DiscordRoles) > 0 || len(mentions.DiscordUsers) > 0 {
+        webhook.Content, webhook.AllowedMentions = buildMentions(mentions)
+    }
+
+    webhookBody, err := json.Marshal(webhook)
+    if err != nil {
+        return nil, fmt.Errorf("failed to marshal webhook body: %w", err)
+    }
+
+    req, err := http.NewRequest(method, requestURL, bytes.NewBuffer(webhookBody))
+    if err != nil {
+        return nil, fmt.Errorf("failed to create request: %w", err)
+    }
+    req.Header.Set("Content-Type", "application/json")
+
+    resp, err := http.DefaultClient.Do(req)
+    if err != nil {
+        return nil, fmt.Errorf("failed to send webhook: %w", err)
+    }
+    return resp, nil
+}
+
+func convertEmbed(embed output.MessageEmbed) DiscordEmbed {
+    if embed.Spoiler {
+        embed.Title = "[NSFW] " + embed.Title
+        if embed.Description != "" {
+            embed.Description = "||" + embed.Description + "||"
+        }
+    }
+
+    return DiscordEmbed{
+        Title:       embed.Title,
+        Description: embed.Description,
+        URL:         embed.URL,
+        Author:      EmbedAuthor{Name: embed.Author},
+        Fields:      convertFields(embed.Fields),
+    }
+}
+
+// buildMentions returns the message content pinging the roles and users, only those are allowed to be pinged
+func buildMentions(mentions output.Mentions) (string, *AllowedMentions) {
+    var pings []string
+    for _, role := range mentions.DiscordRoles {
+        pings = append(pings, fmt.Sprintf("<@&%s>", role))
+    }
+    for _, user := range mentions.DiscordUsers {
+        pings = append(pings, fmt.Sprintf("<@%s>", user))
+    }
+    return strings.Join(pings, " "), &AllowedMentions{
+        Parse: []string{},
+        Roles: mentions.DiscordRoles,
+        Users: mentions.DiscordUsers,
+    }
+}
+
 func convertFields(fields []output.EmbedField) []EmbedField {
     var embedFields []EmbedField
     for _, field := range fields {
@@ -0,0 +1,64 @@
+package discord
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"xenigo/internal/output"
+)
+
+func TestTrackedMessageKeepsWebhookQuery(t *testing.T) {
+	var requests []*http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		json.NewEncoder(w).Encode(map[string]string{"id": "123"})
+	}))
+	defer server.Close()
+
+	sender := &DiscordSender{WebhookURL: server.URL + "/api/webhooks/1/token?thread_id=42"}
+	messageID, err := sender.SendTrackedMessage(output.MessageEmbed{Title: "A post"})
+	if err != nil {
+		t.Fatalf("SendTrackedMessage() error = %v", err)
+	}
+	if messageID != "123" {
+		t.Errorf("SendTrackedMessage() = %q, expected 123", messageID)
+	}
+	if err := sender.EditMessage(messageID, output.MessageEmbed{Title: "An edited post"}); err != nil {
+		t.Fatalf("EditMessage() error = %v", err)
+	}
+
+	sent, edited := requests[0], requests[1]
+	if sent.URL.Path != "/api/webhooks/1/token" || sent.URL.Query().Get("wait") != "true" || sent.URL.Query().Get("thread_id") != "42" {
+		t.Errorf("unexpected send URL %s", sent.URL)
+	}
+	if edited.Method != http.MethodPatch || edited.URL.Path != "/api/webhooks/1/token/messages/123" || edited.URL.Query().Get("thread_id") != "42" {
+		t.Errorf("unexpected edit %s %s", edited.Method, edited.URL)
+	}
+}
+
+func TestSendMessagesPingsOnce(t *testing.T) {
+	var webhook DiscordWebhook
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&webhook)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	mentions := output.Mentions{DiscordRoles: []string{"1"}, DiscordUsers: []string{"2"}}
+	sender := &DiscordSender{WebhookURL: server.URL}
+	err := sender.SendMessages([]output.MessageEmbed{
+		{Title: "A post", Mentions: mentions},
+		{Title: "Another post", Mentions: mentions},
+		{Title: "A third post", Mentions: output.Mentions{DiscordRoles: []string{"3", "1"}}},
+	})
+	if err != nil {
+		t.Fatalf("SendMessages() error = %v", err)
+	}
+	if webhook.Content != "<@&1> <@&3> <@2>" {
+		t.Errorf("content = %q, expected every mention once", webhook.Content)
+	}
+	if len(webhook.AllowedMentions.Roles) != 2 || len(webhook.AllowedMentions.Users) != 1 {
+		t.Errorf("unexpected allowed mentions %+v", webhook.AllowedMentions)
+	}
+}
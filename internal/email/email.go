@@ -0,0 +1,261 @@
+package email
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"log"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+	"xenigo/internal/output"
+)
+
+// Connection security, matching the options of the email output
+const (
+	securityStartTLS = "starttls"
+	securityTLS      = "tls"
+)
+
+type EmailSender struct {
+	Host     string
+	Port     int
+	Security string
+	Username string // Authentication is skipped without a username
+	Password string
+	From     string // Addresses may include a display name, e.g. "xenigo <xenigo@example.org>"
+	To       []string
+	Target   string // Used in the subject of digests
+}
+
+func (e *EmailSender) SendMessage(embed output.MessageEmbed) error {
+	log.Printf("Sending email: %s", embed.Title)
+	return e.send(embed.Title, []output.MessageEmbed{embed})
+}
+
+// SendMessages sends a digest of the embeds as a single email
+func (e *EmailSender) SendMessages(embeds []output.MessageEmbed) error {
+	subject := fmt.Sprintf("%d new posts in %s", len(embeds), e.Target)
+	log.Printf("Sending email: %s", subject)
+	return e.send(subject, embeds)
+}
+
+func (e *EmailSender) send(subject string, embeds []output.MessageEmbed) error {
+	message, err := e.buildMessage(subject, embeds)
+	if err != nil {
+		return err
+	}
+
+	client, err := e.dial()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if e.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", e.Username, e.Password, e.Host)); err != nil {
+			return fmt.Errorf("failed to authenticate: %w", err)
+		}
+	}
+	if err := client.Mail(bareAddress(e.From)); err != nil {
+		return fmt.Errorf("failed to set sender: %w", err)
+	}
+	for _, recipient := range e.To {
+		if err := client.Rcpt(bareAddress(recipient)); err != nil {
+			return fmt.Errorf("failed to add recipient %s: %w", recipient, err)
+		}
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to start message: %w", err)
+	}
+	if _, err := writer.Write(message); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	return client.Quit()
+}
+
+// dial connects to the server, upgrading the connection with STARTTLS or starting out with TLS
+// depending on the security of the sender
+func (e *EmailSender) dial() (*smtp.Client, error) {
+	address := net.JoinHostPort(e.Host, strconv.Itoa(e.Port))
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+
+	var conn net.Conn
+	var err error
+	if e.Security == securityTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", address, &tls.Config{ServerName: e.Host})
+	} else {
+		conn, err = dialer.Dial("tcp", address)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", address, err)
+	}
+
+	client, err := smtp.NewClient(conn, e.Host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	if e.Security == securityStartTLS {
+		if err := client.StartTLS(&tls.Config{ServerName: e.Host}); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+	return client, nil
+}
+
+// buildMessage renders the embeds as a multipart/alternative message with plain text and HTML bodies
+func (e *EmailSender) buildMessage(subject string, embeds []output.MessageEmbed) ([]byte, error) {
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+	for _, part := range []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=utf-8", formatPlain(embeds)},
+		{"text/html; charset=utf-8", formatHTML(embeds)},
+	} {
+		writer, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create message part: %w", err)
+		}
+		encoder := quotedprintable.NewWriter(writer)
+		if _, err := encoder.Write([]byte(part.content)); err != nil {
+			return nil, fmt.Errorf("failed to encode message part: %w", err)
+		}
+		encoder.Close()
+	}
+	parts.Close()
+
+	var to []string
+	for _, recipient := range e.To {
+		to = append(to, headerAddress(recipient))
+	}
+
+	var message bytes.Buffer
+	headers := []struct{ name, value string }{
+		{"From", headerAddress(e.From)},
+		{"To", strings.Join(to, ", ")},
+		{"Subject", mime.QEncoding.Encode("utf-8", subject)},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"Message-ID", e.messageID()},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", parts.Boundary())},
+	}
+	for _, header := range headers {
+		fmt.Fprintf(&message, "%s: %s\r\n", header.name, header.value)
+	}
+	message.WriteString("\r\n")
+	message.Write(body.Bytes())
+	return message.Bytes(), nil
+}
+
+// bareAddress strips the display name, the SMTP envelope only takes the bare address
+func bareAddress(value string) string {
+	if parsed, err := mail.ParseAddress(value); err == nil {
+		return parsed.Address
+	}
+	return value
+}
+
+// headerAddress encodes the display name for use in headers
+func headerAddress(value string) string {
+	if parsed, err := mail.ParseAddress(value); err == nil {
+		return parsed.String()
+	}
+	return value
+}
+
+func (e *EmailSender) messageID() string {
+	random := make([]byte, 12)
+	rand.Read(random)
+	domain := e.Host
+	if from := bareAddress(e.From); strings.Contains(from, "@") {
+		domain = from[strings.LastIndex(from, "@")+1:]
+	}
+	return fmt.Sprintf("<%s.%s@%s>", strconv.FormatInt(time.Now().UnixNano(), 36), hex.EncodeToString(random), domain)
+}
+
+func formatPlain(embeds []output.MessageEmbed) string {
+	var sections []string
+	for _, embed := range embeds {
+		var b strings.Builder
+		b.WriteString(embed.Title)
+		if embed.URL != "" {
+			fmt.Fprintf(&b, "\n%s", embed.URL)
+		}
+		if embed.Author != "" {
+			fmt.Fprintf(&b, "\nby u/%s", embed.Author)
+		}
+		if embed.Description != "" {
+			description := embed.Description
+			if embed.Spoiler {
+				description = "NSFW content hidden, open the post to read it."
+			}
+			fmt.Fprintf(&b, "\n\n%s", description)
+		}
+		if len(embed.Fields) > 0 {
+			b.WriteString("\n")
+		}
+		for _, field := range embed.Fields {
+			fmt.Fprintf(&b, "\n%s: %s", field.Name, field.Value)
+		}
+		sections = append(sections, b.String())
+	}
+	return strings.Join(sections, "\n\n---\n\n") + "\n"
+}
+
+func formatHTML(embeds []output.MessageEmbed) string {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html><body>\n")
+	for i, embed := range embeds {
+		if i > 0 {
+			b.WriteString("<hr>\n")
+		}
+		title := html.EscapeString(embed.Title)
+		if embed.URL != "" {
+			title = fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(embed.URL), title)
+		}
+		fmt.Fprintf(&b, "<h3>%s</h3>\n", title)
+		if embed.Author != "" {
+			fmt.Fprintf(&b, "<p><em>by u/%s</em></p>\n", html.EscapeString(embed.Author))
+		}
+		if embed.ImageURL != "" && !embed.Spoiler {
+			fmt.Fprintf(&b, "<p><img src=\"%s\" alt=\"%s\" style=\"max-width: 100%%\"></p>\n", html.EscapeString(embed.ImageURL), html.EscapeString(embed.Title))
+		}
+		if embed.Description != "" {
+			if embed.Spoiler {
+				b.WriteString("<p><em>NSFW content hidden, open the post to read it.</em></p>\n")
+			} else {
+				fmt.Fprintf(&b, "<p style=\"white-space: pre-wrap\">%s</p>\n", html.EscapeString(embed.Description))
+			}
+		}
+		if len(embed.Fields) > 0 {
+			b.WriteString("<table>\n")
+			for _, field := range embed.Fields {
+				fmt.Fprintf(&b, "<tr><th align=\"left\">%s</th><td>%s</td></tr>\n", html.EscapeString(field.Name), html.EscapeString(field.Value))
+			}
+			b.WriteString("</table>\n")
+		}
+	}
+	b.WriteString("</body></html>\n")
+	return b.String()
+}
@@ -0,0 +1,133 @@
+package email
+
+import (
+	"bufio"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+	"xenigo/internal/output"
+)
+
+// serveSMTP accepts a single session without TLS or authentication and returns the envelope and message
+func serveSMTP(listener net.Listener) <-chan []string {
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+		reply("220 localhost ESMTP")
+
+		var session []string
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			switch {
+			case strings.HasPrefix(line, "EHLO"), strings.HasPrefix(line, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(line, "MAIL FROM:"), strings.HasPrefix(line, "RCPT TO:"):
+				session = append(session, line)
+				reply("250 OK")
+			case line == "DATA":
+				reply("354 Go ahead")
+				var data strings.Builder
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil || line == ".\r\n" {
+						break
+					}
+					data.WriteString(line)
+				}
+				session = append(session, data.String())
+				reply("250 OK")
+			case line == "QUIT":
+				reply("221 Bye")
+				received <- session
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+	return received
+}
+
+func TestSendMessage(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	received := serveSMTP(listener)
+
+	sender := &EmailSender{
+		Host:     "127.0.0.1",
+		Port:     listener.Addr().(*net.TCPAddr).Port,
+		Security: "none",
+		From:     "xenigo <xenigo@example.org>",
+		To:       []string{"Jöhn <john@example.org>", "jane@example.org"},
+	}
+	err = sender.SendMessage(output.MessageEmbed{
+		Title:       "Café <3",
+		Description: "A post & its body",
+		URL:         "https://example.org/post",
+		Fields:      []output.EmbedField{{Name: "Subreddit", Value: "golang"}},
+	})
+	if err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+
+	session := <-received
+	if len(session) != 4 || session[0] != "MAIL FROM:<xenigo@example.org>" || session[1] != "RCPT TO:<john@example.org>" || session[2] != "RCPT TO:<jane@example.org>" {
+		t.Fatalf("unexpected envelope %q", session)
+	}
+
+	message, err := mail.ReadMessage(strings.NewReader(session[3]))
+	if err != nil {
+		t.Fatalf("failed to parse message: %v", err)
+	}
+	subject, _ := new(mime.WordDecoder).DecodeHeader(message.Header.Get("Subject"))
+	if subject != "Café <3" {
+		t.Errorf("Subject = %q", subject)
+	}
+
+	_, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatalf("invalid Content-Type: %v", err)
+	}
+	parts := multipart.NewReader(message.Body, params["boundary"])
+	expected := map[string]string{
+		"text/plain; charset=utf-8": "A post & its body",
+		"text/html; charset=utf-8":  "A post &amp; its body",
+	}
+	for {
+		part, err := parts.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("failed to read part: %v", err)
+		}
+		body, _ := io.ReadAll(quotedprintable.NewReader(part))
+		contentType := part.Header.Get("Content-Type")
+		if !strings.Contains(string(body), expected[contentType]) {
+			t.Errorf("%s part does not contain %q:\n%s", contentType, expected[contentType], body)
+		}
+		delete(expected, contentType)
+	}
+	if len(expected) > 0 {
+		t.Errorf("missing parts %v", expected)
+	}
+}
@@ -0,0 +1,293 @@
+package file
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"xenigo/internal/output"
+	"xenigo/internal/reddit"
+)
+
+// Formats, matching the options of the file and stdout outputs
+const (
+	FormatJSONL    = "jsonl"
+	FormatCSV      = "csv"
+	FormatMarkdown = "markdown"
+)
+
+// Rotation, matching the options of the file output
+const (
+	RotateSize  = "size"
+	RotateDaily = "daily"
+)
+
+var csvHeader = []string{"time", "target", "id", "subreddit", "author", "title", "url", "discussion_url", "flair", "score", "num_comments", "created_utc", "price"}
+
+// Every file is written by a single sender at a time, as several targets may share one file
+var (
+	locks   = make(map[string]*sync.Mutex)
+	locksMu sync.Mutex
+)
+
+func lock(path string) *sync.Mutex {
+	locksMu.Lock()
+	defer locksMu.Unlock()
+	if _, ok := locks[path]; !ok {
+		locks[path] = &sync.Mutex{}
+	}
+	return locks[path]
+}
+
+// record is a post as it is archived, digests and held posts only carry the embed
+type record struct {
+	Time   time.Time          `json:"time"`
+	Target string             `json:"target"`
+	Title  string             `json:"title"`
+	URL    string             `json:"url"`
+	Author string             `json:"author"`
+	Post   *reddit.RedditPost `json:"post,omitempty"`
+	Fields map[string]string  `json:"fields,omitempty"`
+}
+
+func newRecord(data output.PostData, target string) record {
+	if data.Target != "" {
+		target = data.Target
+	}
+	r := record{
+		Time:   time.Now().UTC(),
+		Target: target,
+		Title:  data.Embed.Title,
+		URL:    data.Embed.URL,
+		Author: data.Embed.Author,
+		Fields: data.Fields,
+	}
+	if post, ok := data.Post.(reddit.RedditPost); ok {
+		r.Post = &post
+	}
+	return r
+}
+
+type FileSender struct {
+	Path    string
+	Format  string
+	Rotate  string // Rotation by size or daily, none when empty
+	MaxSize int64  // Size in bytes after which the file is rotated
+	Target  string // Recorded for digests and held posts, which only carry the embed
+}
+
+func (f *FileSender) SendMessage(embed output.MessageEmbed) error {
+	return f.SendPost(output.PostData{Embed: embed})
+}
+
+// SendMessages writes a record per embed, so digests end up in the file just like single posts
+func (f *FileSender) SendMessages(embeds []output.MessageEmbed) error {
+	for _, embed := range embeds {
+		if err := f.SendPost(output.PostData{Embed: embed}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *FileSender) SendPost(data output.PostData) error {
+	mu := lock(f.Path)
+	mu.Lock()
+	defer mu.Unlock()
+
+	entry, err := encode(f.Format, newRecord(data, f.Target))
+	if err != nil {
+		return err
+	}
+
+	if err := f.rotate(int64(len(entry))); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(f.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	if f.Format == FormatCSV {
+		if info, err := file.Stat(); err == nil && info.Size() == 0 {
+			if err := writeCSV(file, csvHeader); err != nil {
+				return err
+			}
+		}
+	}
+	if _, err := file.Write(entry); err != nil {
+		return fmt.Errorf("failed to write to file: %w", err)
+	}
+	return nil
+}
+
+// rotate moves the file aside once it would grow beyond the maximum size, or when it was last written on an earlier day.
+// The rotated file keeps the name of the file with the time it was rotated, or the day it was written, appended.
+func (f *FileSender) rotate(size int64) error {
+	info, err := os.Stat(f.Path)
+	if err != nil || info.Size() == 0 {
+		return nil
+	}
+
+	var suffix string
+	switch f.Rotate {
+	case RotateSize:
+		if info.Size()+size <= f.MaxSize {
+			return nil
+		}
+		suffix = time.Now().Format("20060102-150405")
+	case RotateDaily:
+		modified := info.ModTime().Format("2006-01-02")
+		if modified == time.Now().Format("2006-01-02") {
+			return nil
+		}
+		suffix = modified
+	default:
+		return nil
+	}
+
+	// Never overwrite an earlier rotation, e.g. when the file fills up several times within a second
+	extension := filepath.Ext(f.Path)
+	base := fmt.Sprintf("%s-%s", strings.TrimSuffix(f.Path, extension), suffix)
+	rotated := base + extension
+	for i := 1; ; i++ {
+		if _, err := os.Stat(rotated); os.IsNotExist(err) {
+			break
+		}
+		rotated = fmt.Sprintf("%s.%d%s", base, i, extension)
+	}
+	if err := os.Rename(f.Path, rotated); err != nil {
+		return fmt.Errorf("failed to rotate file: %w", err)
+	}
+	return nil
+}
+
+type StdoutSender struct {
+	Format string
+	Target string // Recorded for digests and held posts, which only carry the embed
+}
+
+var (
+	stdoutMu     sync.Mutex
+	stdoutHeader bool
+)
+
+func (s *StdoutSender) SendMessage(embed output.MessageEmbed) error {
+	return s.SendPost(output.PostData{Embed: embed})
+}
+
+// SendMessages writes a record per embed, so digests are written just like single posts
+func (s *StdoutSender) SendMessages(embeds []output.MessageEmbed) error {
+	for _, embed := range embeds {
+		if err := s.SendPost(output.PostData{Embed: embed}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *StdoutSender) SendPost(data output.PostData) error {
+	entry, err := encode(s.Format, newRecord(data, s.Target))
+	if err != nil {
+		return err
+	}
+
+	stdoutMu.Lock()
+	defer stdoutMu.Unlock()
+	if s.Format == FormatCSV && !stdoutHeader {
+		if err := writeCSV(os.Stdout, csvHeader); err != nil {
+			return err
+		}
+		stdoutHeader = true
+	}
+	if _, err := os.Stdout.Write(entry); err != nil {
+		return fmt.Errorf("failed to write to stdout: %w", err)
+	}
+	return nil
+}
+
+func encode(format string, r record) ([]byte, error) {
+	switch format {
+	case FormatCSV:
+		var buf bytes.Buffer
+		if err := writeCSV(&buf, csvRow(r)); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case FormatMarkdown:
+		return []byte(formatMarkdown(r)), nil
+	default:
+		entry, err := json.Marshal(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal post: %w", err)
+		}
+		return append(entry, '\n'), nil
+	}
+}
+
+func writeCSV(w io.Writer, row []string) error {
+	writer := csv.NewWriter(w)
+	writer.Write(row)
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write csv: %w", err)
+	}
+	return nil
+}
+
+func csvRow(r record) []string {
+	row := []string{r.Time.Format(time.RFC3339), r.Target, "", "", r.Author, r.Title, r.URL, "", "", "", "", "", r.Fields["price"]}
+	if r.Post != nil {
+		row[2] = r.Post.Name
+		row[3] = r.Post.Subreddit
+		row[7] = r.Post.DiscussionURL()
+		row[8] = r.Post.LinkFlairText
+		row[9] = strconv.Itoa(r.Post.Score)
+		row[10] = strconv.Itoa(r.Post.NumComments)
+		if r.Post.CreatedUTC > 0 {
+			row[11] = time.Unix(int64(r.Post.CreatedUTC), 0).UTC().Format(time.RFC3339)
+		}
+	}
+	return row
+}
+
+var markdownEscaper = strings.NewReplacer("[", `\[`, "]", `\]`)
+
+func formatMarkdown(r record) string {
+	var b strings.Builder
+	title := markdownEscaper.Replace(r.Title)
+	if r.URL != "" {
+		title = fmt.Sprintf("[%s](%s)", title, r.URL)
+	}
+	fmt.Fprintf(&b, "## %s\n\n", title)
+
+	details := []string{r.Time.Format("2006-01-02 15:04 MST")}
+	if r.Author != "" {
+		details = append(details, "u/"+r.Author)
+	}
+	if r.Post != nil {
+		if r.Post.Subreddit != "" {
+			details = append(details, "r/"+r.Post.Subreddit)
+		}
+		details = append(details, fmt.Sprintf("[discussion](%s)", r.Post.DiscussionURL()))
+	}
+	fmt.Fprintf(&b, "*%s*\n\n", strings.Join(details, " · "))
+
+	if r.Post != nil && r.Post.Selftext != "" {
+		fmt.Fprintf(&b, "%s\n\n", r.Post.Selftext)
+	}
+	if r.Fields["price"] != "" {
+		fmt.Fprintf(&b, "- **Price:** %s\n\n", strings.TrimSpace(r.Fields["price"]+" "+r.Fields["currency"]))
+	}
+	b.WriteString("---\n\n")
+	return b.String()
+}
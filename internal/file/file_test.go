@@ -0,0 +1,78 @@
+package file
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"xenigo/internal/output"
+	"xenigo/internal/reddit"
+)
+
+func TestFileSenderCSVRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "posts.csv")
+	sender := &FileSender{Path: path, Format: FormatCSV, Rotate: RotateSize, MaxSize: 300}
+
+	for _, title := range []string{"First, with a comma", "Second", "Third"} {
+		err := sender.SendPost(output.PostData{
+			Target: "GPUs",
+			Post:   reddit.RedditPost{Name: "t3_" + title, Title: title, Subreddit: "hardwareswap", Permalink: "/r/hardwareswap/comments/abc/"},
+			Fields: map[string]string{"price": "450"},
+			Embed:  output.MessageEmbed{Title: title},
+		})
+		if err != nil {
+			t.Fatalf("SendPost() error = %v", err)
+		}
+	}
+
+	files, _ := filepath.Glob(filepath.Join(filepath.Dir(path), "posts*.csv"))
+	if len(files) < 2 {
+		t.Fatalf("expected the file to be rotated, got %v", files)
+	}
+	var all strings.Builder
+	for _, file := range files {
+		data, _ := os.ReadFile(file)
+		all.Write(data)
+		if !strings.HasPrefix(string(data), strings.Join(csvHeader, ",")+"\n") {
+			t.Errorf("%s does not start with the header:\n%s", file, data)
+		}
+		if info, _ := os.Stat(file); info.Size() > 300 {
+			t.Errorf("%s is %d bytes, larger than the maximum size", file, info.Size())
+		}
+	}
+
+	for _, expected := range []string{`,"First, with a comma",`, ",Second,", ",Third,", "https://www.reddit.com/r/hardwareswap/comments/abc/"} {
+		if !strings.Contains(all.String(), expected) {
+			t.Errorf("files do not contain %s:\n%s", expected, all.String())
+		}
+	}
+}
+
+func TestFileSenderJSONL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "posts.jsonl")
+	sender := &FileSender{Path: path, Format: FormatJSONL, Target: "Cats"}
+
+	if err := sender.SendPost(output.PostData{Target: "Cats", Post: reddit.RedditPost{Name: "t3_abc", Score: 42}}); err != nil {
+		t.Fatalf("SendPost() error = %v", err)
+	}
+	// Digests only carry the embed
+	if err := sender.SendMessages([]output.MessageEmbed{{Title: "Held post"}}); err != nil {
+		t.Fatalf("SendMessages() error = %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d:\n%s", len(lines), data)
+	}
+	var first, second record
+	json.Unmarshal([]byte(lines[0]), &first)
+	json.Unmarshal([]byte(lines[1]), &second)
+	if first.Post == nil || first.Post.Name != "t3_abc" || first.Post.Score != 42 {
+		t.Errorf("unexpected first record %+v", first)
+	}
+	if second.Post != nil || second.Title != "Held post" || second.Target != "Cats" {
+		t.Errorf("unexpected second record %+v", second)
+	}
+}
@@ -0,0 +1,135 @@
+package filter
+
+import (
+	"strconv"
+	"strings"
+	"xenigo/internal/config"
+	"xenigo/internal/market"
+	"xenigo/internal/reddit"
+)
+
+// Fields returns the values of a post that filters can match on, including the fields extracted by the parser of the target.
+func Fields(post reddit.RedditPost, target config.Target) map[string]string {
+	fields := map[string]string{
+		"title":     post.Title,
+		"selftext":  post.Selftext,
+		"author":    post.Author,
+		"subreddit": post.Subreddit,
+		"flair":     post.LinkFlairText,
+		"url":       post.URL,
+	}
+
+	if target.Parser == config.ParserMarket {
+		if listing, ok := market.Parse(post.Title); ok {
+			fields["location"] = listing.Location
+			fields["have"] = listing.Have
+			fields["want"] = listing.Want
+		}
+	}
+	if price, ok := market.LowestPrice(post.Title, post.Selftext); ok {
+		fields["price"] = strconv.FormatFloat(price.Amount, 'f', -1, 64)
+		fields["currency"] = price.Currency
+	}
+	return fields
+}
+
+// MatchKeywords reports whether any of the keywords is mentioned within its price ceiling.
+// Posts always match when no keywords are configured.
+func MatchKeywords(keywords []config.Keyword, fields map[string]string) bool {
+	if len(keywords) == 0 {
+		return true
+	}
+
+	// Sellers list what they have, so only match that side of trading posts
+	text, ok := fields["have"]
+	if !ok {
+		text = fields["title"]
+	}
+	text = strings.ToLower(text)
+
+	for _, keyword := range keywords {
+		match := strings.ToLower(keyword.Match)
+		if !strings.Contains(text, match) {
+			continue
+		}
+		if keyword.MaxPrice == 0 {
+			return true
+		}
+		if price, ok := keywordPrice(match, text, strings.ToLower(fields["selftext"])); ok && price.Amount <= keyword.MaxPrice {
+			return true
+		}
+	}
+	return false
+}
+
+// keywordPrice returns the price listed for the keyword, the first price following its first mention
+// on the same line. The texts are searched in order, e.g. the title before the body of the post.
+func keywordPrice(keyword string, texts ...string) (market.Price, bool) {
+	for _, text := range texts {
+		i := strings.Index(text, keyword)
+		if i < 0 {
+			continue
+		}
+		line := text[i+len(keyword):]
+		if end := strings.IndexByte(line, '\n'); end >= 0 {
+			line = line[:end]
+		}
+		if prices := market.ExtractPrices(line); len(prices) > 0 {
+			return prices[0], true
+		}
+	}
+	return market.Price{}, false
+}
+
+// Match reports whether the fields satisfy all of the filters. Unknown fields never match.
+func Match(filters []config.Filter, fields map[string]string) bool {
+	for _, filter := range filters {
+		value, ok := fields[strings.ToLower(filter.Field)]
+		if !ok || !matchFilter(filter, value) {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchingMentions returns the mention rules whose pattern matches their field.
+func MatchingMentions(rules []config.MentionRule, fields map[string]string) []config.MentionRule {
+	var matching []config.MentionRule
+	for _, rule := range rules {
+		// Patterns are validated when loading the config
+		if pattern, err := rule.Pattern(); err == nil && pattern.MatchString(fields[strings.ToLower(rule.Field)]) {
+			matching = append(matching, rule)
+		}
+	}
+	return matching
+}
+
+func matchFilter(filter config.Filter, value string) bool {
+	if filter.Min != nil || filter.Max != nil {
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return false
+		}
+		if (filter.Min != nil && number < *filter.Min) || (filter.Max != nil && number > *filter.Max) {
+			return false
+		}
+	}
+	value = strings.ToLower(value)
+	if filter.Contains != "" && !strings.Contains(value, strings.ToLower(filter.Contains)) {
+		return false
+	}
+	if filter.StartsWith != "" && !strings.HasPrefix(value, strings.ToLower(filter.StartsWith)) {
+		return false
+	}
+	if filter.Equals != "" && value != strings.ToLower(filter.Equals) {
+		return false
+	}
+	if filter.Matches != "" {
+		// Patterns are validated when loading the config
+		pattern, err := filter.Pattern()
+		if err != nil || !pattern.MatchString(value) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,113 @@
+package filter
+
+import (
+	"testing"
+	"xenigo/internal/config"
+	"xenigo/internal/reddit"
+)
+
+func TestMatchKeywordsMaxPrice(t *testing.T) {
+	keywords := []config.Keyword{{Match: "4090", MaxPrice: 1000}}
+	market := config.Target{Parser: config.ParserMarket}
+
+	tests := []struct {
+		name     string
+		post     reddit.RedditPost
+		target   config.Target
+		expected bool
+	}{
+		{"price of another item in the title", reddit.RedditPost{Title: "[USA-CA] [H] 4090 $1600, cable $5 [W] PayPal"}, market, false},
+		{"price of another item before the keyword", reddit.RedditPost{Title: "[USA-CA] [H] cable $5, 4090 $1600 [W] PayPal"}, market, false},
+		{"listed within the ceiling", reddit.RedditPost{Title: "[USA-CA] [H] cable $50, 4090 $950 [W] PayPal"}, market, true},
+		{"price in the body", reddit.RedditPost{
+			Title:    "[USA-CA] [H] 4090, cable [W] PayPal",
+			Selftext: "Cable - $5\n4090 FE - $1600 shipped",
+		}, market, false},
+		{"price in the body within the ceiling", reddit.RedditPost{
+			Title:    "[USA-CA] [H] 4090, cable [W] PayPal",
+			Selftext: "4090 FE - 900 shipped\nCable - $5",
+		}, market, true},
+		{"price on another line of the body", reddit.RedditPost{
+			Title:    "[USA-CA] [H] 4090, cable [W] PayPal",
+			Selftext: "4090 FE, make an offer\nCable - $5",
+		}, market, false},
+		{"no price", reddit.RedditPost{Title: "[USA-CA] [H] 4090 [W] Local cash"}, market, false},
+		{"without the market parser", reddit.RedditPost{Title: "Selling 4090 for $800, cable $5"}, config.Target{}, true},
+		{"keyword in the want part", reddit.RedditPost{Title: "[USA-CA] [H] PayPal $500 [W] 4090"}, market, false},
+	}
+	for _, test := range tests {
+		if matched := MatchKeywords(keywords, Fields(test.post, test.target)); matched != test.expected {
+			t.Errorf("%s: MatchKeywords() = %t, expected %t", test.name, matched, test.expected)
+		}
+	}
+}
+
+func TestMatchKeywordsWithoutCeiling(t *testing.T) {
+	fields := Fields(reddit.RedditPost{Title: "[USA-CA] [H] RTX 4090 [W] PayPal"}, config.Target{Parser: config.ParserMarket})
+	if !MatchKeywords([]config.Keyword{{Match: "rtx 3080"}, {Match: "RTX 4090"}}, fields) {
+		t.Error("keyword without price ceiling did not match")
+	}
+	if MatchKeywords([]config.Keyword{{Match: "3080"}}, fields) {
+		t.Error("keyword that isn't mentioned matched")
+	}
+	if !MatchKeywords(nil, fields) {
+		t.Error("posts always match without keywords")
+	}
+}
+
+func TestMatch(t *testing.T) {
+	low, high := 100.0, 500.0
+	fields := map[string]string{
+		"title": "[USA-CA] [H] RTX 4090 FE [W] PayPal",
+		"flair": "Selling",
+		"price": "450",
+		"want":  "PayPal",
+	}
+
+	tests := []struct {
+		name     string
+		filters  []config.Filter
+		expected bool
+	}{
+		{"no filters", nil, true},
+		{"within min and max", []config.Filter{{Field: "price", Min: &low, Max: &high}}, true},
+		{"below min", []config.Filter{{Field: "price", Min: &high}}, false},
+		{"above max", []config.Filter{{Field: "price", Max: &low}}, false},
+		{"non-numeric value against min", []config.Filter{{Field: "want", Min: &low}}, false},
+		{"non-numeric value against max", []config.Filter{{Field: "title", Max: &high}}, false},
+		{"contains ignoring case", []config.Filter{{Field: "title", Contains: "rtx 4090"}}, true},
+		{"does not contain", []config.Filter{{Field: "title", Contains: "3080"}}, false},
+		{"starts with", []config.Filter{{Field: "title", StartsWith: "[usa-"}}, true},
+		{"does not start with", []config.Filter{{Field: "title", StartsWith: "[EU"}}, false},
+		{"equals ignoring case", []config.Filter{{Field: "flair", Equals: "selling"}}, true},
+		{"does not equal", []config.Filter{{Field: "flair", Equals: "Sell"}}, false},
+		{"matches ignoring case", []config.Filter{{Field: "title", Matches: `rtx \d{4} fe`}}, true},
+		{"does not match", []config.Filter{{Field: "flair", Matches: "^buying$"}}, false},
+		{"field name ignoring case", []config.Filter{{Field: "Flair", Equals: "Selling"}}, true},
+		{"unknown field", []config.Filter{{Field: "location", Contains: "CA"}}, false},
+		{"all filters must match", []config.Filter{{Field: "flair", Equals: "Selling"}, {Field: "price", Max: &low}}, false},
+	}
+	for _, test := range tests {
+		if matched := Match(test.filters, fields); matched != test.expected {
+			t.Errorf("%s: Match() = %t, expected %t", test.name, matched, test.expected)
+		}
+	}
+}
+
+func TestMatchingMentions(t *testing.T) {
+	rules := []config.MentionRule{
+		{Match: "4090", Field: "title", DiscordRoles: []string{"1"}},
+		{Match: "^selling$", Field: "flair", DiscordRoles: []string{"1"}, DiscordUsers: []string{"2"}},
+		{Match: "3080", Field: "title", DiscordRoles: []string{"3"}},
+		{Match: "4090", Field: "selftext", SlackUsers: []string{"U1"}},
+	}
+	fields := map[string]string{"title": "[H] RTX 4090 [W] PayPal", "flair": "Selling", "selftext": ""}
+
+	matching := MatchingMentions(rules, fields)
+	if len(matching) != 2 || matching[0].Field != "title" || matching[1].Field != "flair" {
+		t.Errorf("MatchingMentions() = %+v, expected the title and flair rules", matching)
+	}
+	if MatchingMentions(rules, map[string]string{"title": "[H] 6800 [W] PayPal"}) != nil {
+		t.Error("rules matched a post that mentions none of them")
+	}
+}
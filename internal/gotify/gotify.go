@@ -0,0 +1,91 @@
+package gotify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"xenigo/internal/output"
+)
+
+type GotifySender struct {
+	ServerURL string
+	AppToken  string
+	Priority  *int // 0 to 10, the default priority of the application when nil
+}
+
+type messageRequest struct {
+	Title    string                 `json:"title"`
+	Message  string                 `json:"message"`
+	Priority *int                   `json:"priority,omitempty"`
+	Extras   map[string]interface{} `json:"extras,omitempty"`
+}
+
+func (g *GotifySender) SendMessage(embed output.MessageEmbed) error {
+	log.Printf("Sending message to Gotify: %s", embed.Title)
+
+	// Render the message as markdown and open the post when the notification is clicked
+	extras := map[string]interface{}{
+		"client::display": map[string]string{"contentType": "text/markdown"},
+	}
+	notification := map[string]interface{}{}
+	if embed.URL != "" {
+		notification["click"] = map[string]string{"url": embed.URL}
+	}
+	if embed.ImageURL != "" && !embed.Spoiler {
+		notification["bigImageUrl"] = embed.ImageURL
+	}
+	if len(notification) > 0 {
+		extras["client::notification"] = notification
+	}
+
+	body, err := json.Marshal(messageRequest{
+		Title:    embed.Title,
+		Message:  formatMarkdown(embed),
+		Priority: g.Priority,
+		Extras:   extras,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, g.ServerURL+"/message", bytes.NewBuffer(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Gotify-Key", g.AppToken)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("received non-200 response code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func formatMarkdown(embed output.MessageEmbed) string {
+	var b strings.Builder
+	if embed.URL != "" {
+		fmt.Fprintf(&b, "[Open post](%s)", embed.URL)
+	}
+	if embed.Author != "" {
+		fmt.Fprintf(&b, "\n\n*by u/%s*", embed.Author)
+	}
+	if embed.Description != "" && !embed.Spoiler {
+		fmt.Fprintf(&b, "\n\n%s", embed.Description)
+	}
+	if len(embed.Fields) > 0 {
+		b.WriteString("\n")
+	}
+	for _, field := range embed.Fields {
+		fmt.Fprintf(&b, "\n- **%s:** %s", field.Name, field.Value)
+	}
+	return output.MessageOrTitle(strings.TrimSpace(b.String()), embed)
+}
@@ -0,0 +1,89 @@
+package gotify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"xenigo/internal/output"
+)
+
+func TestSendMessage(t *testing.T) {
+	var request map[string]interface{}
+	var path, key string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, key = r.URL.Path, r.Header.Get("X-Gotify-Key")
+		json.NewDecoder(r.Body).Decode(&request)
+	}))
+	defer server.Close()
+
+	priority := 8
+	sender := &GotifySender{ServerURL: server.URL, AppToken: "app-token", Priority: &priority}
+	err := sender.SendMessage(output.MessageEmbed{
+		Title:       "[GPU] 4090",
+		Description: "Great deal",
+		URL:         "https://example.org/deal",
+		Author:      "someone",
+		ImageURL:    "https://i.redd.it/gpu.jpg",
+		Fields:      []output.EmbedField{{Name: "Price", Value: "1599 USD"}},
+	})
+	if err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+
+	if path != "/message" || key != "app-token" {
+		t.Errorf("unexpected request to %s with key %q", path, key)
+	}
+	if request["title"] != "[GPU] 4090" || request["priority"] != float64(8) {
+		t.Errorf("unexpected request %v", request)
+	}
+	expected := "[Open post](https://example.org/deal)\n\n*by u/someone*\n\nGreat deal\n\n- **Price:** 1599 USD"
+	if request["message"] != expected {
+		t.Errorf("message = %q, expected %q", request["message"], expected)
+	}
+
+	extras := request["extras"].(map[string]interface{})
+	if display := extras["client::display"].(map[string]interface{}); display["contentType"] != "text/markdown" {
+		t.Errorf("unexpected display extras %v", display)
+	}
+	notification := extras["client::notification"].(map[string]interface{})
+	if notification["click"].(map[string]interface{})["url"] != "https://example.org/deal" || notification["bigImageUrl"] != "https://i.redd.it/gpu.jpg" {
+		t.Errorf("unexpected notification extras %v", notification)
+	}
+}
+
+func TestSendMessageDefaults(t *testing.T) {
+	var request map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&request)
+	}))
+	defer server.Close()
+
+	sender := &GotifySender{ServerURL: server.URL}
+	if err := sender.SendMessage(output.MessageEmbed{Title: "A post", Description: "NSFW body", Spoiler: true}); err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+
+	// Without a priority the default priority of the application applies
+	if _, ok := request["priority"]; ok {
+		t.Errorf("priority %v sent, expected none", request["priority"])
+	}
+	if request["message"] != "A post" {
+		t.Errorf("message = %q, expected the title as the NSFW description is hidden", request["message"])
+	}
+	if _, ok := request["extras"].(map[string]interface{})["client::notification"]; ok {
+		t.Error("notification extras sent without a link or image")
+	}
+}
+
+func TestSendMessageError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	sender := &GotifySender{ServerURL: server.URL, AppToken: "wrong"}
+	if err := sender.SendMessage(output.MessageEmbed{Title: "A post"}); err == nil {
+		t.Error("SendMessage() expected an error for a rejected token")
+	}
+}
@@ -0,0 +1,443 @@
+package irc
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"time"
+	"xenigo/internal/output"
+)
+
+const (
+	maxTextLength = 400 // Leaves room for the command and the prefix the server adds within the 512 byte line limit
+	queueSize     = 100
+	readTimeout   = 6 * time.Minute // Servers ping idle clients well within this
+	writeTimeout  = 10 * time.Second
+	minBackoff    = 5 * time.Second
+	maxBackoff    = 5 * time.Minute
+)
+
+var (
+	// How long posts wait for a new client to register, later posts fail right away while it is not connected
+	registerTimeout = 15 * time.Second
+	// Lines are still queued for this long after the connection was lost, the client reconnects well within it
+	reconnectGrace = 30 * time.Second
+)
+
+type IRCSender struct {
+	Server           string // host:port
+	TLS              bool
+	Nick             string
+	Username         string
+	RealName         string
+	Password         string // Server password, sent with PASS
+	SASLUsername     string // Authenticates with SASL PLAIN when set
+	SASLPassword     string
+	NickServPassword string   // Identifies with NickServ once connected when set
+	Channels         []string // "#channel" or "#channel key"
+	FloodBurst       int      // Lines sent right away before the delay applies
+	FloodDelay       time.Duration
+}
+
+// SendMessage queues a one-line summary of the embed for every channel. The connection to the server
+// is kept open across posts, lines are sent once it is registered. It fails while the client is unable
+// to connect, rather than queueing lines that may never be sent.
+func (s *IRCSender) SendMessage(embed output.MessageEmbed) error {
+	log.Printf("Sending message to IRC: %s", embed.Title)
+
+	c := getClient(s)
+	if err := c.waitRegistered(registerTimeout); err != nil {
+		return err
+	}
+	line := summary(embed)
+	for _, channel := range s.Channels {
+		if err := c.send(fmt.Sprintf("PRIVMSG %s :%s", strings.Fields(channel)[0], line)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// client is a persistent connection to a server, shared by all targets using the same connection settings
+type client struct {
+	config   IRCSender
+	queue    chan string
+	pending  string // Line that failed to send and is retried after reconnecting
+	channels []string
+
+	mu             sync.Mutex
+	conn           net.Conn
+	registered     bool
+	started        time.Time
+	ready          chan struct{} // Closed once registered, replaced when the connection is lost
+	lastRegistered time.Time     // When the connection was last lost while registered
+}
+
+var (
+	clients   = make(map[connection]*client)
+	clientsMu sync.Mutex
+)
+
+// connection holds everything but the channels of a sender. Targets only share a client when all
+// of it matches, so none of them ends up with the credentials or flood settings of another.
+type connection struct {
+	server, nick, username, realName     string
+	password, saslUsername, saslPassword string
+	nickServPassword                     string
+	tls                                  bool
+	floodBurst                           int
+	floodDelay                           time.Duration
+}
+
+func getClient(s *IRCSender) *client {
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+
+	key := connection{
+		server:           s.Server,
+		nick:             s.Nick,
+		username:         s.Username,
+		realName:         s.RealName,
+		password:         s.Password,
+		saslUsername:     s.SASLUsername,
+		saslPassword:     s.SASLPassword,
+		nickServPassword: s.NickServPassword,
+		tls:              s.TLS,
+		floodBurst:       s.FloodBurst,
+		floodDelay:       s.FloodDelay,
+	}
+	c, ok := clients[key]
+	if !ok {
+		c = &client{config: *s, queue: make(chan string, queueSize), started: time.Now(), ready: make(chan struct{})}
+		clients[key] = c
+		go c.run()
+	}
+	c.join(s.Channels)
+	return c
+}
+
+// waitRegistered reports an error unless the client is registered. A client that lost its registered
+// connection only moments ago counts as registered, as it is about to reconnect, and a new client is
+// given until the timeout after it started to register.
+func (c *client) waitRegistered(timeout time.Duration) error {
+	c.mu.Lock()
+	ready := c.ready
+	recent := c.registered || (!c.lastRegistered.IsZero() && time.Since(c.lastRegistered) < reconnectGrace)
+	wait := time.Until(c.started.Add(timeout))
+	if !c.lastRegistered.IsZero() {
+		wait = 0
+	}
+	c.mu.Unlock()
+	if recent {
+		return nil
+	}
+
+	select {
+	case <-ready:
+		return nil
+	case <-time.After(wait):
+		return fmt.Errorf("not connected to IRC server %s", c.config.Server)
+	}
+}
+
+func (c *client) send(line string) error {
+	select {
+	case c.queue <- line:
+		return nil
+	default:
+		return errors.New("IRC message queue is full")
+	}
+}
+
+// join adds the channels of a target, joining them right away when already connected
+func (c *client) join(channels []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, channel := range channels {
+		known := false
+		for _, existing := range c.channels {
+			known = known || existing == channel
+		}
+		if known {
+			continue
+		}
+		c.channels = append(c.channels, channel)
+		if c.registered {
+			c.writeLocked("JOIN " + strings.Join(strings.Fields(channel), " "))
+		}
+	}
+}
+
+// run keeps the client connected, reconnecting with an increasing delay
+func (c *client) run() {
+	backoff := minBackoff
+	for {
+		registered, err := c.session()
+		log.Printf("IRC connection to %s lost: %v", c.config.Server, err)
+		if registered {
+			backoff = minBackoff
+		}
+		time.Sleep(backoff)
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// session connects and registers with the server, then sends queued lines until the connection fails
+func (c *client) session() (bool, error) {
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	var conn net.Conn
+	var err error
+	if c.config.TLS {
+		host, _, _ := net.SplitHostPort(c.config.Server)
+		conn, err = tls.DialWithDialer(dialer, "tcp", c.config.Server, &tls.Config{ServerName: host})
+	} else {
+		conn, err = dialer.Dial("tcp", c.config.Server)
+	}
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	c.mu.Lock()
+	c.conn = conn
+	c.registered = false
+	c.mu.Unlock()
+
+	registered := make(chan struct{})
+	done := make(chan struct{})
+	var writer sync.WaitGroup
+	writer.Add(1)
+	go func() {
+		defer writer.Done()
+		c.writeLoop(registered, done)
+	}()
+
+	err = c.readLoop(conn, registered)
+
+	c.mu.Lock()
+	wasRegistered := c.registered
+	if wasRegistered {
+		c.lastRegistered = time.Now()
+		c.ready = make(chan struct{})
+	}
+	c.registered = false
+	c.conn = nil
+	c.mu.Unlock()
+
+	close(done)
+	conn.Close()
+	writer.Wait()
+	return wasRegistered, err
+}
+
+func (c *client) readLoop(conn net.Conn, registered chan struct{}) error {
+	nick := c.config.Nick
+	if c.config.SASLUsername != "" {
+		c.write("CAP REQ :sasl")
+	}
+	if c.config.Password != "" {
+		c.write("PASS " + c.config.Password)
+	}
+	c.write("NICK " + nick)
+	c.write(fmt.Sprintf("USER %s 0 * :%s", c.config.Username, c.config.RealName))
+
+	reader := bufio.NewReader(conn)
+	for {
+		conn.SetReadDeadline(time.Now().Add(readTimeout))
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		command, params := parse(strings.TrimRight(line, "\r\n"))
+
+		switch command {
+		case "PING":
+			c.write("PONG :" + last(params))
+		case "CAP":
+			if len(params) >= 3 && params[1] == "ACK" && strings.Contains(last(params), "sasl") {
+				c.write("AUTHENTICATE PLAIN")
+			} else if len(params) >= 3 && params[1] == "NAK" {
+				return errors.New("server does not support SASL")
+			}
+		case "AUTHENTICATE":
+			if last(params) == "+" {
+				credentials := c.config.SASLUsername + "\x00" + c.config.SASLUsername + "\x00" + c.config.SASLPassword
+				c.write("AUTHENTICATE " + base64.StdEncoding.EncodeToString([]byte(credentials)))
+			}
+		case "903": // SASL authentication successful
+			c.write("CAP END")
+		case "902", "904", "905", "906", "908":
+			return fmt.Errorf("SASL authentication failed: %s", last(params))
+		case "433": // Nickname in use
+			nick += "_"
+			c.write("NICK " + nick)
+		case "001": // Welcome, registration is complete
+			if c.config.NickServPassword != "" {
+				c.write("PRIVMSG NickServ :IDENTIFY " + c.config.NickServPassword)
+			}
+			c.mu.Lock()
+			if !c.registered {
+				close(c.ready)
+			}
+			c.registered = true
+			for _, channel := range c.channels {
+				c.writeLocked("JOIN " + strings.Join(strings.Fields(channel), " "))
+			}
+			c.mu.Unlock()
+			close(registered)
+		case "ERROR":
+			return fmt.Errorf("server closed the connection: %s", last(params))
+		}
+	}
+}
+
+// writeLoop sends the queued lines once registered, allowing a burst of lines
+// before falling back to one line every flood delay. Without a flood delay lines are sent right away.
+func (c *client) writeLoop(registered, done chan struct{}) {
+	select {
+	case <-registered:
+	case <-done:
+		return
+	}
+
+	limited := c.config.FloodDelay > 0 && c.config.FloodBurst > 0
+	tokens := c.config.FloodBurst
+	refilled := time.Now()
+	for {
+		line := c.pending
+		if line == "" {
+			select {
+			case line = <-c.queue:
+			case <-done:
+				return
+			}
+		}
+
+		if limited && tokens < c.config.FloodBurst {
+			gained := int(time.Since(refilled) / c.config.FloodDelay)
+			tokens += gained
+			refilled = refilled.Add(time.Duration(gained) * c.config.FloodDelay)
+			if tokens >= c.config.FloodBurst {
+				tokens = c.config.FloodBurst
+				refilled = time.Now()
+			}
+		}
+		if limited && tokens == 0 {
+			c.pending = line
+			select {
+			case <-time.After(time.Until(refilled.Add(c.config.FloodDelay))):
+				continue
+			case <-done:
+				return
+			}
+		}
+
+		if err := c.write(line); err != nil {
+			c.pending = line
+			return
+		}
+		c.pending = ""
+		tokens--
+	}
+}
+
+func (c *client) write(line string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.writeLocked(line)
+}
+
+func (c *client) writeLocked(line string) error {
+	if c.conn == nil {
+		return errors.New("not connected")
+	}
+	c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	_, err := fmt.Fprintf(c.conn, "%s\r\n", line)
+	return err
+}
+
+// parse splits a message into its command and parameters, dropping the prefix
+func parse(line string) (string, []string) {
+	if strings.HasPrefix(line, "@") { // Message tags
+		if i := strings.IndexByte(line, ' '); i >= 0 {
+			line = line[i+1:]
+		}
+	}
+	if strings.HasPrefix(line, ":") {
+		if i := strings.IndexByte(line, ' '); i >= 0 {
+			line = line[i+1:]
+		}
+	}
+
+	var trailing string
+	hasTrailing := false
+	if i := strings.Index(line, " :"); i >= 0 {
+		line, trailing, hasTrailing = line[:i], line[i+2:], true
+	}
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return "", nil
+	}
+	params := fields[1:]
+	if hasTrailing {
+		params = append(params, trailing)
+	}
+	return strings.ToUpper(fields[0]), params
+}
+
+func last(params []string) string {
+	if len(params) == 0 {
+		return ""
+	}
+	return params[len(params)-1]
+}
+
+// summary renders the embed as a single line: the title in bold, followed by the subreddit, author, price and link
+func summary(embed output.MessageEmbed) string {
+	var details []string
+	for _, field := range embed.Fields {
+		switch field.Name {
+		case "Subreddit":
+			details = append(details, "r/"+field.Value)
+		case "Price":
+			details = append(details, field.Value)
+		}
+	}
+	if embed.Author != "" {
+		details = append(details, "u/"+embed.Author)
+	}
+	if embed.URL != "" {
+		details = append(details, embed.URL)
+	}
+	suffix := ""
+	if len(details) > 0 {
+		suffix = " · " + clean(strings.Join(details, " · "))
+	}
+
+	title := clean(embed.Title)
+	if embed.Spoiler {
+		title = "[NSFW] " + title
+	}
+	// The title gives way to the details, which hold the link
+	if room := maxTextLength - len(suffix) - 2; len(title) > room {
+		title = output.TruncateBytes(title, room)
+	}
+	return "\x02" + title + "\x02" + suffix
+}
+
+// clean replaces the line breaks and NUL bytes that would end the line early
+func clean(text string) string {
+	return strings.Join(strings.FieldsFunc(text, func(r rune) bool {
+		return r == '\r' || r == '\n' || r == '\x00'
+	}), " ")
+}
+
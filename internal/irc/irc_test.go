@@ -0,0 +1,189 @@
+package irc
+
+import (
+	"bufio"
+	"encoding/base64"
+	"net"
+	"strings"
+	"testing"
+	"time"
+	"xenigo/internal/output"
+)
+
+// serveIRC registers a single client with SASL and forwards every line it sends
+func serveIRC(listener net.Listener) <-chan string {
+	lines := make(chan string, 100)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+		reader := bufio.NewReader(conn)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				close(lines)
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			lines <- line
+
+			switch {
+			case line == "CAP REQ :sasl":
+				reply(":irc.example.org CAP * ACK :sasl")
+			case line == "AUTHENTICATE PLAIN":
+				reply("AUTHENTICATE +")
+			case strings.HasPrefix(line, "AUTHENTICATE "):
+				reply(":irc.example.org 903 xenigo :SASL authentication successful")
+			case line == "NICK xenigo":
+				reply(":irc.example.org 433 * xenigo :Nickname is already in use")
+			case strings.HasPrefix(line, "USER "):
+				reply(":irc.example.org 001 xenigo_ :Welcome")
+				reply("PING :check")
+			}
+		}
+	}()
+	return lines
+}
+
+func TestSendMessage(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	lines := serveIRC(listener)
+
+	sender := &IRCSender{
+		Server:           listener.Addr().String(),
+		Nick:             "xenigo",
+		Username:         "xenigo",
+		RealName:         "xenigo",
+		SASLUsername:     "account",
+		SASLPassword:     "secret",
+		NickServPassword: "identify",
+		Channels:         []string{"#deals", "#private key"},
+		FloodBurst:       1,
+		FloodDelay:       50 * time.Millisecond,
+	}
+	for _, title := range []string{"First\r\nQUIT", "Second"} {
+		err := sender.SendMessage(output.MessageEmbed{
+			Title:  title,
+			URL:    "https://example.org/post",
+			Fields: []output.EmbedField{{Name: "Subreddit", Value: "hardwareswap"}, {Name: "Price", Value: "450 USD"}},
+		})
+		if err != nil {
+			t.Fatalf("SendMessage() error = %v", err)
+		}
+	}
+
+	credentials := base64.StdEncoding.EncodeToString([]byte("account\x00account\x00secret"))
+	expected := []string{
+		"CAP REQ :sasl",
+		"NICK xenigo",
+		"USER xenigo 0 * :xenigo",
+		"AUTHENTICATE PLAIN",
+		"NICK xenigo_",
+		"AUTHENTICATE " + credentials,
+		"CAP END",
+		"PRIVMSG NickServ :IDENTIFY identify",
+		"JOIN #deals",
+		"JOIN #private key",
+		"PONG :check",
+		"PRIVMSG #deals :\x02First QUIT\x02 · r/hardwareswap · 450 USD · https://example.org/post",
+		"PRIVMSG #private :\x02First QUIT\x02 · r/hardwareswap · 450 USD · https://example.org/post",
+		"PRIVMSG #deals :\x02Second\x02 · r/hardwareswap · 450 USD · https://example.org/post",
+		"PRIVMSG #private :\x02Second\x02 · r/hardwareswap · 450 USD · https://example.org/post",
+	}
+
+	received := make(map[string]bool)
+	var order []string
+	timeout := time.After(5 * time.Second)
+	for len(order) < len(expected) {
+		select {
+		case line := <-lines:
+			received[line] = true
+			order = append(order, line)
+		case <-timeout:
+			t.Fatalf("timed out, received %q", order)
+		}
+	}
+	for _, line := range expected {
+		if !received[line] {
+			t.Errorf("server did not receive %q, got %q", line, order)
+		}
+	}
+
+	// The channel messages arrive in order
+	var messages []string
+	for _, line := range order {
+		if strings.HasPrefix(line, "PRIVMSG #") {
+			messages = append(messages, line)
+		}
+	}
+	for i, line := range expected[len(expected)-4:] {
+		if i >= len(messages) || messages[i] != line {
+			t.Errorf("message %d = %q, expected %q", i, messages, line)
+		}
+	}
+}
+
+func TestSummaryLength(t *testing.T) {
+	line := summary(output.MessageEmbed{Title: strings.Repeat("é", 500), URL: "https://example.org/post"})
+	if len(line) > maxTextLength {
+		t.Errorf("summary() is %d bytes, limit is %d", len(line), maxTextLength)
+	}
+	if !strings.HasSuffix(line, "https://example.org/post") {
+		t.Errorf("summary() lost the link: %q", line)
+	}
+}
+
+func TestGetClientSettings(t *testing.T) {
+	// Nothing listens here, the clients keep trying to connect in the background
+	base := IRCSender{Server: "127.0.0.1:1", Nick: "xenigo-pool", Username: "xenigo", RealName: "xenigo", Channels: []string{"#a"}}
+	first := getClient(&base)
+
+	other := base
+	other.Channels = []string{"#b"}
+	if getClient(&other) != first {
+		t.Error("targets with the same connection settings don't share a client")
+	}
+
+	for name, change := range map[string]func(s *IRCSender){
+		"password":          func(s *IRCSender) { s.Password = "secret" },
+		"sasl":              func(s *IRCSender) { s.SASLUsername, s.SASLPassword = "xenigo", "secret" },
+		"nickserv password": func(s *IRCSender) { s.NickServPassword = "secret" },
+		"flood burst":       func(s *IRCSender) { s.FloodBurst = 10 },
+		"flood delay":       func(s *IRCSender) { s.FloodDelay = time.Second },
+	} {
+		changed := base
+		change(&changed)
+		if c := getClient(&changed); c == first || c.config.Password != changed.Password || c.config.FloodBurst != changed.FloodBurst {
+			t.Errorf("target with a different %s reuses the client of another target", name)
+		}
+	}
+}
+
+func TestSendMessageWithoutConnection(t *testing.T) {
+	timeout := registerTimeout
+	registerTimeout = 100 * time.Millisecond
+	defer func() { registerTimeout = timeout }()
+
+	// Nothing listens here, the client keeps trying to connect in the background
+	sender := &IRCSender{Server: "127.0.0.1:1", Nick: "offline", Channels: []string{"#deals"}}
+	if err := sender.SendMessage(output.MessageEmbed{Title: "Lost"}); err == nil {
+		t.Error("SendMessage() accepted a post without a connection")
+	}
+	if queued := len(getClient(sender).queue); queued != 0 {
+		t.Errorf("%d lines were queued without a connection", queued)
+	}
+
+	// Once the client had its time to register, posts fail right away
+	start := time.Now()
+	if err := sender.SendMessage(output.MessageEmbed{Title: "Lost"}); err == nil || time.Since(start) > registerTimeout/2 {
+		t.Errorf("SendMessage() error = %v after %s, expected to fail right away", err, time.Since(start))
+	}
+}
@@ -0,0 +1,62 @@
+package market
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Listing is the structured form of a trading post title such as "[USA-CA] [H] RTX 4090 [W] PayPal, Local Cash"
+type Listing struct {
+	Location string
+	Have     string
+	Want     string
+}
+
+var tagPattern = regexp.MustCompile(`\[([^\]]*)\]`)
+
+// Parse extracts the location, have and want parts of a trading post title.
+// It reports false if the title contains neither a [H] nor a [W] tag.
+func Parse(title string) (Listing, bool) {
+	var listing Listing
+	var current *string
+	found := false
+
+	rest := title
+	for {
+		loc := tagPattern.FindStringSubmatchIndex(rest)
+		if loc == nil {
+			appendPart(current, rest)
+			break
+		}
+		appendPart(current, rest[:loc[0]])
+
+		tag := strings.TrimSpace(rest[loc[2]:loc[3]])
+		switch strings.ToUpper(tag) {
+		case "H":
+			current, found = &listing.Have, true
+		case "W":
+			current, found = &listing.Want, true
+		default:
+			// The first other tag is the location, any later ones belong to the surrounding text
+			if listing.Location == "" && current == nil {
+				listing.Location = tag
+			} else {
+				appendPart(current, rest[loc[0]:loc[1]])
+			}
+		}
+		rest = rest[loc[1]:]
+	}
+
+	if !found {
+		return Listing{}, false
+	}
+	listing.Have = strings.TrimSpace(listing.Have)
+	listing.Want = strings.TrimSpace(listing.Want)
+	return listing, true
+}
+
+func appendPart(part *string, text string) {
+	if part != nil {
+		*part += text
+	}
+}
@@ -0,0 +1,48 @@
+package market
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		title    string
+		expected Listing
+		ok       bool
+	}{
+		{
+			name:     "Have before want",
+			title:    "[USA-CA] [H] RTX 4090 FE [W] PayPal, Local Cash",
+			expected: Listing{Location: "USA-CA", Have: "RTX 4090 FE", Want: "PayPal, Local Cash"},
+			ok:       true,
+		},
+		{
+			name:     "Want before have",
+			title:    "[EU-DE][W] Ryzen 7800X3D [H] PayPal",
+			expected: Listing{Location: "EU-DE", Have: "PayPal", Want: "Ryzen 7800X3D"},
+			ok:       true,
+		},
+		{
+			name:     "Lowercase tags and brackets in item",
+			title:    "[USA-NY] [h] Keyboard [65%] [w] paypal",
+			expected: Listing{Location: "USA-NY", Have: "Keyboard [65%]", Want: "paypal"},
+			ok:       true,
+		},
+		{
+			name:  "Not a trading post",
+			title: "[META] Monthly confirmed trade thread",
+			ok:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listing, ok := Parse(tt.title)
+			if ok != tt.ok {
+				t.Fatalf("Parse() ok = %v, expected %v", ok, tt.ok)
+			}
+			if listing != tt.expected {
+				t.Errorf("Parse() = %+v, expected %+v", listing, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package market
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Price is an amount found in a post, Currency is an ISO code when known
+type Price struct {
+	Amount   float64
+	Currency string
+}
+
+var (
+	// $450, € 300, £1,200.50
+	symbolBeforePattern = regexp.MustCompile(`([$€£])\s?(\d{1,3}(?:[,.]\d{3})+|\d+)(?:\.(\d{1,2}))?\b`)
+	// 450$, 300 €, 450 shipped, 450 USD, 300 OBO
+	amountBeforePattern = regexp.MustCompile(`(?i)\b(\d{1,3}(?:,\d{3})+|\d+)(?:\.(\d{1,2}))?\s?(\$|€|£|usd\b|eur\b|gbp\b|shipped\b|obo\b)`)
+)
+
+var currencySymbols = map[string]string{
+	"$":   "USD",
+	"€":   "EUR",
+	"£":   "GBP",
+	"usd": "USD",
+	"eur": "EUR",
+	"gbp": "GBP",
+}
+
+type priceMatch struct {
+	start int
+	end   int
+	price Price
+}
+
+// ExtractPrices returns all prices mentioned in the text in order of appearance.
+func ExtractPrices(text string) []Price {
+	var matches []priceMatch
+
+	for _, m := range symbolBeforePattern.FindAllStringSubmatchIndex(text, -1) {
+		amount, ok := parseAmount(text[m[4]:m[5]], submatch(text, m, 3))
+		if ok {
+			matches = append(matches, priceMatch{m[0], m[1], Price{Amount: amount, Currency: currencySymbols[text[m[2]:m[3]]]}})
+		}
+	}
+	for _, m := range amountBeforePattern.FindAllStringSubmatchIndex(text, -1) {
+		if overlaps(matches, m[0], m[1]) {
+			continue
+		}
+		amount, ok := parseAmount(text[m[2]:m[3]], submatch(text, m, 2))
+		if ok {
+			// "shipped" and "obo" don't tell the currency
+			currency := currencySymbols[strings.ToLower(text[m[6]:m[7]])]
+			matches = append(matches, priceMatch{m[0], m[1], Price{Amount: amount, Currency: currency}})
+		}
+	}
+
+	// Order by position in the text
+	for i := 1; i < len(matches); i++ {
+		for j := i; j > 0 && matches[j].start < matches[j-1].start; j-- {
+			matches[j], matches[j-1] = matches[j-1], matches[j]
+		}
+	}
+
+	prices := make([]Price, 0, len(matches))
+	for _, m := range matches {
+		prices = append(prices, m.price)
+	}
+	return prices
+}
+
+// LowestPrice returns the lowest price mentioned in the title, falling back to the body of the post.
+func LowestPrice(title string, body string) (Price, bool) {
+	prices := ExtractPrices(title)
+	if len(prices) == 0 {
+		prices = ExtractPrices(body)
+	}
+	if len(prices) == 0 {
+		return Price{}, false
+	}
+
+	lowest := prices[0]
+	for _, price := range prices[1:] {
+		if price.Amount < lowest.Amount {
+			lowest = price
+		}
+	}
+	return lowest, true
+}
+
+func submatch(text string, indexes []int, group int) string {
+	if indexes[group*2] < 0 {
+		return ""
+	}
+	return text[indexes[group*2]:indexes[group*2+1]]
+}
+
+func overlaps(matches []priceMatch, start int, end int) bool {
+	for _, m := range matches {
+		if m.start < end && start < m.end {
+			return true
+		}
+	}
+	return false
+}
+
+// parseAmount parses the whole part, which may use "," or "." as thousands separator, and the optional cents
+func parseAmount(whole string, cents string) (float64, bool) {
+	whole = strings.NewReplacer(",", "", ".", "").Replace(whole)
+	if cents != "" {
+		whole += "." + cents
+	}
+	amount, err := strconv.ParseFloat(whole, 64)
+	if err != nil {
+		return 0, false
+	}
+	return amount, true
+}
@@ -0,0 +1,49 @@
+package market
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractPrices(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected []Price
+	}{
+		{
+			name:     "Dollar sign before amount",
+			text:     "[USA-CA] [H] RTX 3080 $450 [W] PayPal",
+			expected: []Price{{Amount: 450, Currency: "USD"}},
+		},
+		{
+			name:     "Shipped",
+			text:     "3080 FE 450 shipped, 6800 XT 400 obo",
+			expected: []Price{{Amount: 450}, {Amount: 400}},
+		},
+		{
+			name:     "Euro with thousands separator",
+			text:     "Asking €1.200 or 300€ for the case",
+			expected: []Price{{Amount: 1200, Currency: "EUR"}, {Amount: 300, Currency: "EUR"}},
+		},
+		{
+			name:     "Symbol and shipped are one price",
+			text:     "$1,350.99 shipped",
+			expected: []Price{{Amount: 1350.99, Currency: "USD"}},
+		},
+		{
+			name:     "No prices",
+			text:     "[H] 4090 [W] 3 local trades",
+			expected: []Price{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prices := ExtractPrices(tt.text)
+			if !reflect.DeepEqual(prices, tt.expected) {
+				t.Errorf("ExtractPrices() = %+v, expected %+v", prices, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package matrix
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+	"xenigo/internal/output"
+)
+
+const (
+	maxDescriptionLength = 16000 // Events are limited to 64 KiB, leave plenty of room for escaping
+	maxAttempts          = 3
+	defaultRetryInterval = 2 * time.Second
+	maxRetryAfter        = time.Minute // Caps how long a rate limited send waits for the homeserver
+)
+
+type MatrixSender struct {
+	HomeserverURL string
+	AccessToken   string
+	RoomID        string
+	RetryInterval time.Duration // Defaults to 2 seconds
+}
+
+type messageContent struct {
+	MsgType       string          `json:"msgtype"`
+	Body          string          `json:"body"`
+	Format        string          `json:"format"`
+	FormattedBody string          `json:"formatted_body"`
+	NewContent    *messageContent `json:"m.new_content,omitempty"`
+	RelatesTo     *relation       `json:"m.relates_to,omitempty"`
+}
+
+type relation struct {
+	RelType string `json:"rel_type"`
+	EventID string `json:"event_id"`
+}
+
+type sendResponse struct {
+	EventID      string `json:"event_id"`
+	ErrCode      string `json:"errcode"`
+	Error        string `json:"error"`
+	RetryAfterMs int64  `json:"retry_after_ms"` // Only set when rate limited
+}
+
+var transactions uint64
+
+func (m *MatrixSender) SendMessage(embed output.MessageEmbed) error {
+	_, err := m.SendTrackedMessage(embed)
+	return err
+}
+
+// SendTrackedMessage sends the embed and returns the event id, so it can be replaced when the post changes
+func (m *MatrixSender) SendTrackedMessage(embed output.MessageEmbed) (string, error) {
+	log.Printf("Sending message to Matrix: %s", embed.Title)
+	return m.send(buildContent(embed))
+}
+
+// EditMessage replaces the event with an updated version of the embed
+func (m *MatrixSender) EditMessage(eventID string, embed output.MessageEmbed) error {
+	log.Printf("Editing message in Matrix: %s", embed.Title)
+
+	content := buildContent(embed)
+	edit := content
+	edit.Body = "* " + content.Body
+	edit.FormattedBody = "* " + content.FormattedBody
+	edit.NewContent = &content
+	edit.RelatesTo = &relation{RelType: "m.replace", EventID: eventID}
+	_, err := m.send(edit)
+	return err
+}
+
+// send puts the event into the room. Every attempt reuses the same transaction id,
+// so the homeserver ignores retries of an event it already received.
+func (m *MatrixSender) send(content messageContent) (string, error) {
+	body, err := json.Marshal(content)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal event body: %w", err)
+	}
+
+	txnID := fmt.Sprintf("xenigo-%d-%d", time.Now().UnixNano(), atomic.AddUint64(&transactions, 1))
+	requestURL := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s",
+		m.HomeserverURL, url.PathEscape(m.RoomID), url.PathEscape(txnID))
+
+	for attempt := 1; ; attempt++ {
+		eventID, wait, err := m.put(requestURL, body)
+		if err == nil || wait == 0 || attempt == maxAttempts {
+			return eventID, err
+		}
+		log.Printf("Attempt %d: Error sending message to Matrix, retrying in %s: %v", attempt, wait, err)
+		time.Sleep(wait)
+	}
+}
+
+// put sends a single attempt and returns how long to wait before retrying a failure,
+// zero when it is not worth retrying. Rate limited attempts wait as long as the homeserver asks.
+func (m *MatrixSender) put(requestURL string, body []byte) (string, time.Duration, error) {
+	retryInterval := m.RetryInterval
+	if retryInterval == 0 {
+		retryInterval = defaultRetryInterval
+	}
+
+	req, err := http.NewRequest(http.MethodPut, requestURL, bytes.NewReader(body))
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+m.AccessToken)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", retryInterval, fmt.Errorf("failed to send event: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result sendResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil && resp.StatusCode == http.StatusOK {
+		return "", 0, fmt.Errorf("failed to decode response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		err := fmt.Errorf("received non-200 response code: %d, %s %s", resp.StatusCode, result.ErrCode, result.Error)
+		switch {
+		case resp.StatusCode == http.StatusTooManyRequests && result.RetryAfterMs > 0:
+			return "", min(time.Duration(result.RetryAfterMs)*time.Millisecond, maxRetryAfter), err
+		case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError:
+			return "", retryInterval, err
+		}
+		return "", 0, err
+	}
+	return result.EventID, 0, nil
+}
+
+func buildContent(embed output.MessageEmbed) messageContent {
+	embed.Description = output.Truncate(embed.Description, maxDescriptionLength)
+	return messageContent{
+		MsgType:       "m.text",
+		Body:          formatPlain(embed),
+		Format:        "org.matrix.custom.html",
+		FormattedBody: formatHTML(embed),
+	}
+}
+
+// formatPlain renders the fallback body for clients without HTML support
+func formatPlain(embed output.MessageEmbed) string {
+	var b strings.Builder
+	b.WriteString(embed.Title)
+	if embed.URL != "" {
+		fmt.Fprintf(&b, "\n%s", embed.URL)
+	}
+	if embed.Author != "" {
+		fmt.Fprintf(&b, "\nby u/%s", embed.Author)
+	}
+	if embed.Description != "" && !embed.Spoiler {
+		fmt.Fprintf(&b, "\n\n%s", embed.Description)
+	}
+	if len(embed.Fields) > 0 {
+		b.WriteString("\n")
+	}
+	for _, field := range embed.Fields {
+		fmt.Fprintf(&b, "\n%s: %s", field.Name, field.Value)
+	}
+	return b.String()
+}
+
+func formatHTML(embed output.MessageEmbed) string {
+	var b strings.Builder
+	title := escapeHTML(embed.Title)
+	if embed.URL != "" {
+		title = fmt.Sprintf(`<a href="%s">%s</a>`, escapeHTML(embed.URL), title)
+	}
+	fmt.Fprintf(&b, "<strong>%s</strong>", title)
+	if embed.Author != "" {
+		fmt.Fprintf(&b, "<br><em>by u/%s</em>", escapeHTML(embed.Author))
+	}
+	if embed.Description != "" {
+		description := escapeHTML(embed.Description)
+		if embed.Spoiler {
+			description = "<span data-mx-spoiler>" + description + "</span>"
+		}
+		fmt.Fprintf(&b, "<p>%s</p>", description)
+	}
+	if len(embed.Fields) > 0 {
+		b.WriteString("<ul>")
+		for _, field := range embed.Fields {
+			fmt.Fprintf(&b, "<li><strong>%s:</strong> %s</li>", escapeHTML(field.Name), escapeHTML(field.Value))
+		}
+		b.WriteString("</ul>")
+	}
+	return b.String()
+}
+
+var htmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "\n", "<br>")
+
+func escapeHTML(text string) string {
+	return htmlEscaper.Replace(text)
+}
@@ -0,0 +1,100 @@
+package matrix
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"xenigo/internal/output"
+)
+
+func TestSendMessageRetriesWithSameTransaction(t *testing.T) {
+	var paths []string
+	var content messageContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			t.Errorf("expected PUT, got %s", r.Method)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer token" {
+			t.Errorf("unexpected Authorization header %q", auth)
+		}
+		paths = append(paths, r.URL.EscapedPath())
+		if len(paths) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&content)
+		w.Write([]byte(`{"event_id":"$event"}`))
+	}))
+	defer server.Close()
+
+	sender := &MatrixSender{HomeserverURL: server.URL, AccessToken: "token", RoomID: "!room:example.org", RetryInterval: 1}
+	eventID, err := sender.SendTrackedMessage(output.MessageEmbed{
+		Title:       "Cats & <dogs>",
+		Description: "line one\nline two",
+		URL:         "https://example.org/cat",
+		Author:      "someone",
+		Fields:      []output.EmbedField{{Name: "Subreddit", Value: "cats"}},
+	})
+	if err != nil {
+		t.Fatalf("SendTrackedMessage() error = %v", err)
+	}
+	if eventID != "$event" {
+		t.Errorf("SendTrackedMessage() = %q, expected $event", eventID)
+	}
+	if len(paths) != 2 || paths[0] != paths[1] {
+		t.Errorf("expected two attempts with the same transaction id, got %v", paths)
+	}
+
+	expected := `<strong><a href="https://example.org/cat">Cats &amp; &lt;dogs&gt;</a></strong><br><em>by u/someone</em><p>line one<br>line two</p><ul><li><strong>Subreddit:</strong> cats</li></ul>`
+	if content.FormattedBody != expected {
+		t.Errorf("formatted_body = %q, expected %q", content.FormattedBody, expected)
+	}
+	if content.MsgType != "m.text" || content.Format != "org.matrix.custom.html" {
+		t.Errorf("unexpected msgtype %q or format %q", content.MsgType, content.Format)
+	}
+}
+
+func TestSendMessageDoesNotRetryClientErrors(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"errcode":"M_FORBIDDEN","error":"not in room"}`))
+	}))
+	defer server.Close()
+
+	sender := &MatrixSender{HomeserverURL: server.URL, AccessToken: "token", RoomID: "!room:example.org", RetryInterval: 1}
+	if err := sender.SendMessage(output.MessageEmbed{Title: "Cats"}); err == nil {
+		t.Fatal("SendMessage() expected an error")
+	}
+	if attempts != 1 {
+		t.Errorf("expected a single attempt, got %d", attempts)
+	}
+}
+
+func TestSendMessageWaitsForRateLimit(t *testing.T) {
+	var attempts []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts = append(attempts, time.Now())
+		if len(attempts) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"errcode":"M_LIMIT_EXCEEDED","error":"Too many requests","retry_after_ms":200}`))
+			return
+		}
+		w.Write([]byte(`{"event_id":"$event"}`))
+	}))
+	defer server.Close()
+
+	sender := &MatrixSender{HomeserverURL: server.URL, AccessToken: "token", RoomID: "!room:example.org", RetryInterval: time.Millisecond}
+	if err := sender.SendMessage(output.MessageEmbed{Title: "Cats"}); err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+	if len(attempts) != 2 {
+		t.Fatalf("expected two attempts, got %d", len(attempts))
+	}
+	if waited := attempts[1].Sub(attempts[0]); waited < 200*time.Millisecond {
+		t.Errorf("retried after %s, expected to wait retry_after_ms", waited)
+	}
+}
@@ -0,0 +1,55 @@
+package mattermost
+
+import (
+	"log"
+	"xenigo/internal/output"
+	"xenigo/internal/slack"
+)
+
+// Mattermost accepts Slack style attachments, but no blocks, and renders their text as markdown
+type MattermostMessage struct {
+	Username    string                  `json:"username,omitempty"`
+	IconURL     string                  `json:"icon_url,omitempty"`
+	Channel     string                  `json:"channel,omitempty"`
+	Attachments []slack.SlackAttachment `json:"attachments"`
+	Props       *MattermostProps        `json:"props,omitempty"`
+}
+
+// MattermostProps holds the card, which is only shown when the info icon of the message is clicked
+type MattermostProps struct {
+	Card string `json:"card"`
+}
+
+type MattermostSender struct {
+	WebhookURL string
+	Username   string // Overrides the name of the webhook, if enabled on the server
+	IconURL    string // Overrides the icon of the webhook, if enabled on the server
+	Channel    string // Overrides the channel of the webhook, e.g. town-square or @username
+}
+
+func (m *MattermostSender) SendMessage(embed output.MessageEmbed) error {
+	log.Printf("Sending message to Mattermost: %s", embed.Title)
+
+	message := MattermostMessage{
+		Username:    m.Username,
+		IconURL:     m.IconURL,
+		Channel:     m.Channel,
+		Attachments: []slack.SlackAttachment{slack.NewAttachment(embed)},
+	}
+	// Spoilers are not supported, so NSFW content moves into the card where it is only shown on request
+	if embed.Spoiler && embed.Description != "" {
+		message.Props = &MattermostProps{Card: embed.Description}
+	}
+	return slack.PostWebhook(m.WebhookURL, message)
+}
+
+// SendMessages sends the embeds as a single message with one attachment per post
+func (m *MattermostSender) SendMessages(embeds []output.MessageEmbed) error {
+	log.Printf("Sending %d posts to Mattermost", len(embeds))
+
+	message := MattermostMessage{Username: m.Username, IconURL: m.IconURL, Channel: m.Channel}
+	for _, embed := range embeds {
+		message.Attachments = append(message.Attachments, slack.NewAttachment(embed))
+	}
+	return slack.PostWebhook(m.WebhookURL, message)
+}
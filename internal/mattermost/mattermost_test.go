@@ -0,0 +1,72 @@
+package mattermost
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"xenigo/internal/output"
+)
+
+func TestSendMessage(t *testing.T) {
+	var message map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&message)
+	}))
+	defer server.Close()
+
+	sender := &MattermostSender{WebhookURL: server.URL, Username: "xenigo", IconURL: "https://example.org/icon.png", Channel: "town-square"}
+	err := sender.SendMessage(output.MessageEmbed{
+		Title:       "A post",
+		Description: "NSFW body",
+		URL:         "https://example.org/post",
+		Author:      "someone",
+		Spoiler:     true,
+		Fields:      []output.EmbedField{{Name: "Subreddit", Value: "selfhosted"}},
+	})
+	if err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"username": "xenigo",
+		"icon_url": "https://example.org/icon.png",
+		"channel":  "town-square",
+	}
+	for key, value := range expected {
+		if message[key] != value {
+			t.Errorf("%s = %v, expected %v", key, message[key], value)
+		}
+	}
+	attachment := message["attachments"].([]interface{})[0].(map[string]interface{})
+	if attachment["title"] != "[NSFW] A post" || attachment["title_link"] != "https://example.org/post" || attachment["author_name"] != "u/someone" || attachment["text"] != "" {
+		t.Errorf("unexpected attachment %v", attachment)
+	}
+	// The NSFW description is only shown in the card
+	if props := message["props"].(map[string]interface{}); props["card"] != "NSFW body" {
+		t.Errorf("unexpected props %v", props)
+	}
+}
+
+func TestSendMessagesWithoutOverrides(t *testing.T) {
+	var message map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&message)
+	}))
+	defer server.Close()
+
+	sender := &MattermostSender{WebhookURL: server.URL}
+	if err := sender.SendMessages([]output.MessageEmbed{{Title: "A post"}, {Title: "Another post"}}); err != nil {
+		t.Fatalf("SendMessages() error = %v", err)
+	}
+
+	// The name, icon and channel of the webhook apply without overrides
+	for _, key := range []string{"username", "icon_url", "channel", "props"} {
+		if _, ok := message[key]; ok {
+			t.Errorf("%s sent without override", key)
+		}
+	}
+	if attachments := message["attachments"].([]interface{}); len(attachments) != 2 {
+		t.Errorf("%d attachments, expected one per post", len(attachments))
+	}
+}
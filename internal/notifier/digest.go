@@ -0,0 +1,161 @@
+package notifier
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+	"xenigo/internal/config"
+	"xenigo/internal/output"
+	"xenigo/internal/schedule"
+)
+
+// A digest that keeps failing to send holds on to at most this many posts, dropping the oldest
+const maxHeldEmbeds = 100
+
+// digest collects the posts of a target and sends them as a single message every window,
+// or once the quiet hours of its output end
+type digest struct {
+	target config.Target
+	embeds []output.MessageEmbed
+	mu     sync.Mutex
+}
+
+var (
+	digests   = make(map[string]*digest)
+	digestsMu sync.Mutex
+)
+
+// queue adds the embed to the digest of the target when it delivers digests or its output is in quiet hours,
+// and reports whether the embed was taken care of. Posts arriving during quiet hours may also be dropped.
+func queue(target config.Target, embed output.MessageEmbed) bool {
+	if schedule.IsQuiet(target.Output.Schedule, time.Now()) {
+		if target.Output.Schedule.DuringQuiet == config.QuietDrop {
+			log.Printf("Dropping post during quiet hours for target %s: %s", target.Name, embed.Title)
+			return true
+		}
+		getDigest(target).add(embed)
+		return true
+	}
+	if target.Delivery != nil && target.Delivery.Mode == config.DeliveryDigest {
+		getDigest(target).add(embed)
+		return true
+	}
+	return false
+}
+
+// getDigest returns the digest of the target, starting it on first use. Target names are unique,
+// the config rejects duplicates and numbers the default names.
+func getDigest(target config.Target) *digest {
+	digestsMu.Lock()
+	defer digestsMu.Unlock()
+
+	d, ok := digests[target.Name]
+	if !ok {
+		d = &digest{target: target}
+		digests[target.Name] = d
+		// Posts held during quiet hours are checked every minute so they go out as soon as quiet hours end
+		window := time.Minute
+		if target.Delivery != nil && target.Delivery.Mode == config.DeliveryDigest {
+			window = time.Duration(target.Delivery.Window) * time.Second
+		}
+		go d.run(window)
+	}
+	return d
+}
+
+func (d *digest) add(embed output.MessageEmbed) {
+	d.mu.Lock()
+	d.embeds = append(d.embeds, compactEmbed(embed))
+	full := len(d.embeds) >= d.target.Delivery.MaxItems
+	d.mu.Unlock()
+
+	if full {
+		d.flush()
+	}
+}
+
+func (d *digest) run(window time.Duration) {
+	ticker := time.NewTicker(window)
+	defer ticker.Stop()
+	for range ticker.C {
+		d.flush()
+	}
+}
+
+func (d *digest) flush() {
+	// Keep holding posts until quiet hours end
+	if schedule.IsQuiet(d.target.Output.Schedule, time.Now()) {
+		return
+	}
+
+	d.mu.Lock()
+	embeds := d.embeds
+	d.embeds = nil
+	d.mu.Unlock()
+
+	if len(embeds) == 0 {
+		return
+	}
+
+	sender := newSender(d.target)
+	if sender == nil {
+		return
+	}
+	log.Printf("Sending digest of %d posts for target: %s", len(embeds), d.target.Name)
+	if err := sendDigest(sender, embeds, d.target.Name); err != nil {
+		log.Printf("Error sending digest, retrying with the next one: %v", err)
+		d.requeue(embeds)
+	}
+}
+
+// requeue puts the posts of a digest that failed to send back in front of the posts added since
+func (d *digest) requeue(embeds []output.MessageEmbed) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.embeds = append(embeds, d.embeds...)
+	if dropped := len(d.embeds) - maxHeldEmbeds; dropped > 0 {
+		log.Printf("Dropping %d posts from the digest of target %s after failing to send it", dropped, d.target.Name)
+		d.embeds = d.embeds[dropped:]
+	}
+}
+
+func sendDigest(sender output.MessageSender, embeds []output.MessageEmbed, name string) error {
+	if batch, ok := sender.(output.BatchSender); ok {
+		return batch.SendMessages(embeds)
+	}
+	return sender.SendMessage(listEmbed(embeds, name))
+}
+
+// compactEmbed drops the body of the post, keeping the title, links and short fields
+func compactEmbed(embed output.MessageEmbed) output.MessageEmbed {
+	compact := embed
+	compact.Description = ""
+	compact.Fields = nil
+	for _, field := range embed.Fields {
+		switch field.Name {
+		case "Subreddit", "Price", "Location":
+			compact.Fields = append(compact.Fields, field)
+		}
+	}
+	return compact
+}
+
+// listEmbed renders the posts as a single list for senders without batch support
+func listEmbed(embeds []output.MessageEmbed, name string) output.MessageEmbed {
+	list := output.MessageEmbed{Title: fmt.Sprintf("%d new posts in %s", len(embeds), name)}
+
+	var lines []string
+	for _, embed := range embeds {
+		line := "• " + embed.Title
+		if embed.URL != "" {
+			line += " - " + embed.URL
+		}
+		lines = append(lines, line)
+		list.Mentions.Add(embed.Mentions)
+	}
+	list.Description = strings.Join(lines, "\n")
+	return list
+}
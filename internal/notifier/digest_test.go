@@ -0,0 +1,68 @@
+package notifier
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"xenigo/internal/config"
+	"xenigo/internal/output"
+)
+
+func TestDigestRetriesFailedSend(t *testing.T) {
+	var titles []string
+	fail := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		var webhook struct {
+			Embeds []struct {
+				Title string `json:"title"`
+			} `json:"embeds"`
+		}
+		json.NewDecoder(r.Body).Decode(&webhook)
+		for _, embed := range webhook.Embeds {
+			titles = append(titles, embed.Title)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	d := &digest{target: config.Target{
+		Name:     "deals",
+		Output:   config.OutputConfig{Type: config.OutputTypeDiscord, WebhookURL: server.URL},
+		Delivery: &config.Delivery{Mode: config.DeliveryDigest, MaxItems: 10},
+	}}
+	d.add(output.MessageEmbed{Title: "First"})
+	d.flush()
+	if len(d.embeds) != 1 {
+		t.Fatalf("digest holds %d posts after failing to send, expected 1", len(d.embeds))
+	}
+
+	fail = false
+	d.add(output.MessageEmbed{Title: "Second"})
+	d.flush()
+	if len(d.embeds) != 0 || fmt.Sprint(titles) != "[First Second]" {
+		t.Errorf("sent %q and kept %d posts, expected both posts in order", titles, len(d.embeds))
+	}
+}
+
+func TestDigestRequeueDropsOldest(t *testing.T) {
+	d := &digest{target: config.Target{Name: "deals"}}
+	var failed []output.MessageEmbed
+	for i := 0; i < maxHeldEmbeds; i++ {
+		failed = append(failed, output.MessageEmbed{Title: fmt.Sprint(i)})
+	}
+	d.embeds = []output.MessageEmbed{{Title: "new"}}
+
+	d.requeue(failed)
+	if len(d.embeds) != maxHeldEmbeds {
+		t.Fatalf("digest holds %d posts, expected %d", len(d.embeds), maxHeldEmbeds)
+	}
+	if d.embeds[0].Title != "1" || d.embeds[maxHeldEmbeds-1].Title != "new" {
+		t.Errorf("kept %q to %q, expected the oldest post to be dropped", d.embeds[0].Title, d.embeds[maxHeldEmbeds-1].Title)
+	}
+}
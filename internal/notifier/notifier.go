@@ -3,43 +3,316 @@ package notifier
 import (
     "fmt"
     "log"
+    "strconv"
+    "strings"
+    "time"
+    "xenigo/internal/command"
     "xenigo/internal/config"
     "xenigo/internal/discord"
+    "xenigo/internal/email"
+    "xenigo/internal/file"
+    "xenigo/internal/filter"
+    "xenigo/internal/irc"
+    "xenigo/internal/gotify"
+    "xenigo/internal/market"
+    "xenigo/internal/matrix"
+    "xenigo/internal/mattermost"
+    "xenigo/internal/ntfy"
+    "xenigo/internal/pushover"
     "xenigo/internal/reddit"
+    "xenigo/internal/rocketchat"
     "xenigo/internal/slack"
+    "xenigo/internal/teams"
+    "xenigo/internal/telegram"
     "xenigo/internal/output"
+    "xenigo/internal/webhook"
 )
 
-func ProcessAndSendPost(post reddit.RedditPost, target config.Target, devFlags *config.DeveloperFlags) {
+// ProcessAndSendPost sends the post to the output of the target. When update tracking is enabled
+// and the output supports it, the id of the sent message is returned so it can be edited later on.
+// It also reports whether the post was sent, or taken care of by a digest or the quiet hours of the output.
+func ProcessAndSendPost(post reddit.RedditPost, target config.Target, devFlags *config.DeveloperFlags) (string, bool) {
+    if config.GetFlag(devFlags.NotifyMute) {
+        log.Printf("Notifications are muted for target: %s", target.Name)
+        return "", false
+    }
+
+    embed := buildEmbed(post, target)
+
+    if queue(target, embed) {
+        return "", true
+    }
+
+    sender := newSender(target)
+    if sender == nil {
+        return "", false
+    }
+
+    if editor, ok := sender.(output.MessageEditor); ok && target.TrackUpdates != nil {
+        messageID, err := editor.SendTrackedMessage(embed)
+        if err != nil {
+            log.Printf("Error sending message: %v", err)
+            return "", false
+        }
+        return messageID, true
+    }
+
+    if err := send(sender, post, embed, target); err != nil {
+        log.Printf("Error sending message: %v", err)
+        return "", false
+    }
+    return "", true
+}
+
+// SendPostUpdate notifies about changes to a previously sent post. The original message is edited
+// when its id is known, otherwise a new message describing the changes is sent.
+func SendPostUpdate(post reddit.RedditPost, changes []string, messageID string, target config.Target, devFlags *config.DeveloperFlags) {
     if config.GetFlag(devFlags.NotifyMute) {
         log.Printf("Notifications are muted for target: %s", target.Name)
         return
     }
 
+    embed := buildEmbed(post, target)
+    embed.Fields = append(embed.Fields, output.EmbedField{Name: "Updates", Value: strings.Join(changes, "\n")})
+
+    sender := newSender(target)
+    if sender == nil {
+        return
+    }
+
+    if editor, ok := sender.(output.MessageEditor); ok && messageID != "" {
+        if err := editor.EditMessage(messageID, embed); err != nil {
+            log.Printf("Error editing message: %v", err)
+        }
+        return
+    }
+
+    embed.Title = "Updated: " + embed.Title
+    if queue(target, embed) {
+        return
+    }
+    if err := send(sender, post, embed, target); err != nil {
+        log.Printf("Error sending message: %v", err)
+    }
+}
+
+// SendTrendingPost notifies that a post crossed one of the trending thresholds of the target.
+func SendTrendingPost(post reddit.RedditPost, reasons []string, target config.Target, devFlags *config.DeveloperFlags) {
+    if config.GetFlag(devFlags.NotifyMute) {
+        log.Printf("Notifications are muted for target: %s", target.Name)
+        return
+    }
+
+    embed := buildEmbed(post, target)
+    embed.Title = "Trending: " + embed.Title
+    embed.Fields = append(embed.Fields,
+        output.EmbedField{Name: "Trending", Value: strings.Join(reasons, "\n")},
+        output.EmbedField{Name: "Score", Value: fmt.Sprintf("%d upvotes, %d comments", post.Score, post.NumComments)},
+    )
+    if queue(target, embed) {
+        return
+    }
+
+    sender := newSender(target)
+    if sender == nil {
+        return
+    }
+    if err := send(sender, post, embed, target); err != nil {
+        log.Printf("Error sending message: %v", err)
+    }
+}
+
+func buildEmbed(post reddit.RedditPost, target config.Target) output.MessageEmbed {
+    // User feeds, multireddits and combined subreddits span many subreddits,
+    // so show where the post actually originates from
+    subreddit := post.Subreddit
+    if subreddit == "" {
+        subreddit = target.Monitor.Subreddit
+    }
+
     embed := output.MessageEmbed{
         Title:       post.Title,
         Description: post.Selftext,
         URL:         post.URL,
         Author:      post.Author,
-        Fields: []output.EmbedField{
-            {Name: "Subreddit", Value: target.Monitor.Subreddit},
-            {Name: "Discussion URL", Value: fmt.Sprintf("https://www.reddit.com%s", post.Permalink)},
-        },
+        ImageURL:    post.ImageURL(),
+        Spoiler:     post.Over18 && target.Content != nil && target.Content.BlurNSFW,
+    }
+    // Private messages have no subreddit, and outputs such as Discord reject empty fields
+    if subreddit != "" {
+        embed.Fields = append(embed.Fields, output.EmbedField{Name: "Subreddit", Value: subreddit})
+    }
+    embed.Fields = append(embed.Fields, output.EmbedField{Name: "Discussion URL", Value: post.DiscussionURL()})
+    if target.Parser == config.ParserMarket {
+        if listing, ok := market.Parse(post.Title); ok {
+            // Not every listing names a location, and outputs such as Discord reject empty fields
+            for _, field := range []output.EmbedField{
+                {Name: "Location", Value: listing.Location},
+                {Name: "Have", Value: listing.Have},
+                {Name: "Want", Value: listing.Want},
+            } {
+                if field.Value != "" {
+                    embed.Fields = append(embed.Fields, field)
+                }
+            }
+        }
+        if price, ok := market.LowestPrice(post.Title, post.Selftext); ok {
+            embed.Fields = append(embed.Fields, output.EmbedField{Name: "Price", Value: formatPrice(price)})
+        }
+    }
+    if reasons := post.ReportReasons(); len(reasons) > 0 {
+        embed.Fields = append(embed.Fields, output.EmbedField{Name: "Reports", Value: strings.Join(reasons, ", ")})
+    }
+    if len(target.Mentions) > 0 {
+        for _, rule := range filter.MatchingMentions(target.Mentions, filter.Fields(post, target)) {
+            embed.Mentions.Add(output.Mentions{
+                DiscordRoles: rule.DiscordRoles,
+                DiscordUsers: rule.DiscordUsers,
+                SlackGroups:  rule.SlackGroups,
+                SlackUsers:   rule.SlackUsers,
+            })
+        }
+    }
+    return embed
+}
+
+// send hands the full post to senders that render it themselves, all others receive the embed
+func send(sender output.MessageSender, post reddit.RedditPost, embed output.MessageEmbed, target config.Target) error {
+    if postSender, ok := sender.(output.PostSender); ok {
+        return postSender.SendPost(output.PostData{
+            Target: target.Name,
+            Post:   post,
+            Fields: filter.Fields(post, target),
+            Embed:  embed,
+        })
     }
+    return sender.SendMessage(embed)
+}
 
-    var sender output.MessageSender
+func formatPrice(price market.Price) string {
+    amount := strconv.FormatFloat(price.Amount, 'f', -1, 64)
+    if price.Currency == "" {
+        return amount
+    }
+    return fmt.Sprintf("%s %s", amount, price.Currency)
+}
+
+func newSender(target config.Target) output.MessageSender {
     log.Printf("Processing target with output type: %s", target.Output.Type) // Add this line for debugging
     switch target.Output.Type {
     case config.OutputTypeDiscord:
-        sender = &discord.DiscordSender{WebhookURL: target.Output.WebhookURL}
+        return &discord.DiscordSender{WebhookURL: target.Output.WebhookURL}
     case config.OutputTypeSlack:
-        sender = &slack.SlackSender{WebhookURL: target.Output.WebhookURL}
+        return &slack.SlackSender{WebhookURL: target.Output.WebhookURL}
+    case config.OutputTypeMattermost:
+        sender := &mattermost.MattermostSender{WebhookURL: target.Output.WebhookURL}
+        if options := target.Output.Mattermost; options != nil {
+            sender.Username, sender.IconURL, sender.Channel = options.Username, options.IconURL, options.Channel
+        }
+        return sender
+    case config.OutputTypeRocketChat:
+        sender := &rocketchat.RocketChatSender{WebhookURL: target.Output.WebhookURL}
+        if options := target.Output.RocketChat; options != nil {
+            sender.Alias, sender.Avatar, sender.Emoji, sender.Channel = options.Alias, options.Avatar, options.Emoji, options.Channel
+        }
+        return sender
+    case config.OutputTypeTeams:
+        return &teams.TeamsSender{WebhookURL: target.Output.WebhookURL}
+    case config.OutputTypeTelegram:
+        return &telegram.TelegramSender{
+            APIURL:     target.Output.Telegram.APIURL,
+            BotToken:   target.Output.Telegram.BotToken,
+            ChatID:     target.Output.Telegram.ChatID,
+            ParseMode:  target.Output.Telegram.ParseMode,
+            SendPhotos: target.Output.Telegram.SendPhotos,
+        }
+    case config.OutputTypeMatrix:
+        return &matrix.MatrixSender{
+            HomeserverURL: target.Output.Matrix.HomeserverURL,
+            AccessToken:   target.Output.Matrix.AccessToken,
+            RoomID:        target.Output.Matrix.RoomID,
+        }
+    case config.OutputTypeEmail:
+        return &email.EmailSender{
+            Host:     target.Output.Email.Host,
+            Port:     target.Output.Email.Port,
+            Security: target.Output.Email.Security,
+            Username: target.Output.Email.Username,
+            Password: target.Output.Email.Password,
+            From:     target.Output.Email.From,
+            To:       target.Output.Email.To,
+            Target:   target.Name,
+        }
+    case config.OutputTypeNtfy:
+        return &ntfy.NtfySender{
+            ServerURL: target.Output.Ntfy.ServerURL,
+            Topic:     target.Output.Ntfy.Topic,
+            Token:     target.Output.Ntfy.Token,
+            Priority:  target.Output.Ntfy.Priority,
+            Tags:      target.Output.Ntfy.Tags,
+        }
+    case config.OutputTypeGotify:
+        return &gotify.GotifySender{
+            ServerURL: target.Output.Gotify.ServerURL,
+            AppToken:  target.Output.Gotify.AppToken,
+            Priority:  target.Output.Gotify.Priority,
+        }
+    case config.OutputTypePushover:
+        return &pushover.PushoverSender{
+            APIURL:   target.Output.Pushover.APIURL,
+            AppToken: target.Output.Pushover.AppToken,
+            UserKey:  target.Output.Pushover.UserKey,
+            Priority: target.Output.Pushover.Priority,
+            Sound:    target.Output.Pushover.Sound,
+            Device:   target.Output.Pushover.Device,
+        }
+    case config.OutputTypeFile:
+        return &file.FileSender{
+            Path:    target.Output.File.Path,
+            Format:  target.Output.File.Format,
+            Rotate:  target.Output.File.Rotate,
+            MaxSize: int64(target.Output.File.MaxSize) * 1024 * 1024,
+            Target:  target.Name,
+        }
+    case config.OutputTypeStdout:
+        return &file.StdoutSender{Format: target.Output.File.Format, Target: target.Name}
+    case config.OutputTypeIRC:
+        return &irc.IRCSender{
+            Server:           target.Output.IRC.Server,
+            TLS:              target.Output.IRC.TLS,
+            Nick:             target.Output.IRC.Nick,
+            Username:         target.Output.IRC.Username,
+            RealName:         target.Output.IRC.RealName,
+            Password:         target.Output.IRC.Password,
+            SASLUsername:     target.Output.IRC.SASLUsername,
+            SASLPassword:     target.Output.IRC.SASLPassword,
+            NickServPassword: target.Output.IRC.NickServPassword,
+            Channels:         target.Output.IRC.Channels,
+            FloodBurst:       target.Output.IRC.FloodBurst,
+            FloodDelay:       time.Duration(target.Output.IRC.FloodDelay) * time.Millisecond,
+        }
+    case config.OutputTypeCommand:
+        return &command.CommandSender{
+            Command:     target.Output.Command.Run,
+            Timeout:     time.Duration(target.Output.Command.Timeout) * time.Second,
+            Concurrency: target.Output.Command.Concurrency,
+            Target:      target.Name,
+        }
+    case config.OutputTypeWebhook:
+        body, err := target.Output.Webhook.BodyTemplate()
+        if err != nil {
+            log.Printf("Error parsing webhook body template: %v", err)
+            return nil
+        }
+        return &webhook.WebhookSender{
+            URL:            target.Output.WebhookURL,
+            Method:         target.Output.Webhook.Method,
+            Headers:        target.Output.Webhook.Headers,
+            ExpectedStatus: target.Output.Webhook.ExpectedStatus,
+            Body:           body,
+        }
     default:
         log.Printf("Unsupported output type: %s", target.Output.Type)
-        return
-    }
-
-    if err := sender.SendMessage(embed); err != nil {
-        log.Printf("Error sending message: %v", err)
+        return nil
     }
-}
\ No newline at end of file
+}
@@ -0,0 +1,65 @@
+package notifier
+
+import (
+	"testing"
+	"xenigo/internal/config"
+	"xenigo/internal/reddit"
+)
+
+func TestBuildEmbedPrivateMessage(t *testing.T) {
+	message := reddit.RedditPost{
+		Title:     "Is the 4090 still available?",
+		Author:    "buyer_1",
+		Permalink: "/message/messages/2ab3c",
+		URL:       "https://www.reddit.com/message/messages/2ab3c",
+	}
+	target := config.Target{Monitor: config.Monitor{Inbox: config.InboxUnread}}
+
+	for _, field := range buildEmbed(message, target).Fields {
+		if field.Value == "" {
+			t.Errorf("field %q is empty", field.Name)
+		}
+		if field.Name == "Subreddit" {
+			t.Error("private message has a Subreddit field")
+		}
+	}
+}
+
+func TestBuildEmbedListingWithoutLocation(t *testing.T) {
+	post := reddit.RedditPost{
+		Title:     "[H] 4090 [W] cash",
+		Subreddit: "hardwareswap",
+		Permalink: "/r/hardwareswap/comments/abc/title/",
+	}
+	target := config.Target{Parser: config.ParserMarket}
+
+	fields := make(map[string]string)
+	for _, field := range buildEmbed(post, target).Fields {
+		if field.Value == "" {
+			t.Errorf("field %q is empty", field.Name)
+		}
+		fields[field.Name] = field.Value
+	}
+	if _, ok := fields["Location"]; ok {
+		t.Error("listing without location has a Location field")
+	}
+	if fields["Have"] != "4090" || fields["Want"] != "cash" {
+		t.Errorf("unexpected fields %q", fields)
+	}
+}
+
+func TestBuildEmbedMentionsOnce(t *testing.T) {
+	post := reddit.RedditPost{Title: "[H] RTX 4090 [W] PayPal", LinkFlairText: "Selling", Subreddit: "hardwareswap"}
+	target := config.Target{Mentions: []config.MentionRule{
+		{Match: "4090", Field: "title", DiscordRoles: []string{"1"}},
+		{Match: "selling", Field: "flair", DiscordRoles: []string{"1"}, DiscordUsers: []string{"2"}},
+	}}
+
+	mentions := buildEmbed(post, target).Mentions
+	if len(mentions.DiscordRoles) != 1 || mentions.DiscordRoles[0] != "1" {
+		t.Errorf("DiscordRoles = %q, expected the role once", mentions.DiscordRoles)
+	}
+	if len(mentions.DiscordUsers) != 1 || mentions.DiscordUsers[0] != "2" {
+		t.Errorf("DiscordUsers = %q", mentions.DiscordUsers)
+	}
+}
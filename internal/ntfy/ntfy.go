@@ -0,0 +1,88 @@
+package ntfy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"xenigo/internal/output"
+)
+
+// Messages longer than 4096 bytes are turned into attachments by ntfy
+const maxMessageLength = 3500
+
+type NtfySender struct {
+	ServerURL string
+	Topic     string
+	Token     string // Access token for protected topics, optional
+	Priority  int    // 1 (min) to 5 (max), the server default when 0
+	Tags      []string
+}
+
+type publishRequest struct {
+	Topic    string   `json:"topic"`
+	Title    string   `json:"title,omitempty"`
+	Message  string   `json:"message"`
+	Priority int      `json:"priority,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Click    string   `json:"click,omitempty"`
+	Attach   string   `json:"attach,omitempty"`
+}
+
+func (n *NtfySender) SendMessage(embed output.MessageEmbed) error {
+	log.Printf("Sending message to ntfy: %s", embed.Title)
+
+	request := publishRequest{
+		Topic:    n.Topic,
+		Title:    embed.Title,
+		Message:  formatMessage(embed),
+		Priority: n.Priority,
+		Tags:     n.Tags,
+		Click:    embed.URL,
+	}
+	if !embed.Spoiler {
+		request.Attach = embed.ImageURL
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	// Publishing as JSON goes to the root of the server, the topic is part of the body
+	req, err := http.NewRequest(http.MethodPost, n.ServerURL, bytes.NewBuffer(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if n.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+n.Token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("received non-200 response code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func formatMessage(embed output.MessageEmbed) string {
+	var lines []string
+	if embed.Author != "" {
+		lines = append(lines, "by u/"+embed.Author)
+	}
+	if embed.Description != "" && !embed.Spoiler {
+		lines = append(lines, output.Truncate(embed.Description, maxMessageLength))
+	}
+	for _, field := range embed.Fields {
+		lines = append(lines, fmt.Sprintf("%s: %s", field.Name, field.Value))
+	}
+	return output.MessageOrTitle(strings.Join(lines, "\n"), embed)
+}
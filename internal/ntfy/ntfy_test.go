@@ -0,0 +1,43 @@
+package ntfy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"xenigo/internal/output"
+)
+
+func TestSendMessage(t *testing.T) {
+	var request publishRequest
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&request)
+	}))
+	defer server.Close()
+
+	sender := &NtfySender{ServerURL: server.URL, Topic: "deals", Token: "tk_secret", Priority: 4, Tags: []string{"moneybag"}}
+	err := sender.SendMessage(output.MessageEmbed{
+		Title:       "[GPU] 4090",
+		Description: "Great deal",
+		URL:         "https://example.org/deal",
+		ImageURL:    "https://i.redd.it/gpu.jpg",
+		Spoiler:     true,
+		Fields:      []output.EmbedField{{Name: "Price", Value: "1599 USD"}},
+	})
+	if err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+
+	if auth != "Bearer tk_secret" {
+		t.Errorf("Authorization = %q", auth)
+	}
+	if request.Topic != "deals" || request.Priority != 4 || request.Click != "https://example.org/deal" || len(request.Tags) != 1 {
+		t.Errorf("unexpected request %+v", request)
+	}
+	// Spoilers hide the description and the image
+	if request.Message != "Price: 1599 USD" || request.Attach != "" {
+		t.Errorf("unexpected message %q or attachment %q", request.Message, request.Attach)
+	}
+}
@@ -4,15 +4,72 @@ type MessageSender interface {
     SendMessage(embed MessageEmbed) error
 }
 
+// BatchSender is implemented by senders that can combine several embeds into a single message
+type BatchSender interface {
+    SendMessages(embeds []MessageEmbed) error
+}
+
+// MessageEditor is implemented by senders that can update a message after it was sent
+type MessageEditor interface {
+    SendTrackedMessage(embed MessageEmbed) (string, error)
+    EditMessage(messageID string, embed MessageEmbed) error
+}
+
+// PostSender is implemented by senders that render the full post themselves instead of only the embed
+type PostSender interface {
+    SendPost(data PostData) error
+}
+
+// PostData is the post as it is handed to senders and templates. Digests and posts held
+// during quiet hours only carry the embed, their post is nil.
+type PostData struct {
+    Target string            `json:"target"`
+    Post   interface{}       `json:"post"`   // The reddit.RedditPost
+    Fields map[string]string `json:"fields"` // The fields available to filters, e.g. price and location
+    Embed  MessageEmbed      `json:"embed"`
+}
+
 type MessageEmbed struct {
-    Title       string
-    Description string
-    URL         string
-    Author      string
-    Fields      []EmbedField
+    Title       string       `json:"title"`
+    Description string       `json:"description,omitempty"`
+    URL         string       `json:"url,omitempty"`
+    Author      string       `json:"author,omitempty"`
+    Fields      []EmbedField `json:"fields,omitempty"`
+    ImageURL    string       `json:"image_url,omitempty"` // Set when the post links directly to an image
+    Spoiler     bool         `json:"spoiler,omitempty"`   // Hide the description behind a spoiler where supported
+    Mentions    Mentions     `json:"-"`
+}
+
+// Mentions are the platform specific ids to ping along with the message
+type Mentions struct {
+    DiscordRoles []string
+    DiscordUsers []string
+    SlackGroups  []string
+    SlackUsers   []string
+}
+
+// Add merges the mentions of another message, so everyone is pinged only once
+func (m *Mentions) Add(other Mentions) {
+    m.DiscordRoles = appendUnique(m.DiscordRoles, other.DiscordRoles)
+    m.DiscordUsers = appendUnique(m.DiscordUsers, other.DiscordUsers)
+    m.SlackGroups = appendUnique(m.SlackGroups, other.SlackGroups)
+    m.SlackUsers = appendUnique(m.SlackUsers, other.SlackUsers)
+}
+
+func appendUnique(ids []string, more []string) []string {
+    for _, id := range more {
+        known := false
+        for _, existing := range ids {
+            known = known || existing == id
+        }
+        if !known {
+            ids = append(ids, id)
+        }
+    }
+    return ids
 }
 
 type EmbedField struct {
-    Name  string
-    Value string
+    Name  string `json:"name"`
+    Value string `json:"value"`
 }
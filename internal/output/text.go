@@ -0,0 +1,41 @@
+package output
+
+import "unicode/utf8"
+
+const ellipsis = "…"
+
+// Truncate shortens the text to at most length characters, marking the cut with an ellipsis
+func Truncate(text string, length int) string {
+	if utf8.RuneCountInString(text) <= length {
+		return text
+	}
+	if length <= 1 {
+		return ""
+	}
+	return string([]rune(text)[:length-1]) + ellipsis
+}
+
+// TruncateBytes shortens the text to at most length bytes without splitting a character,
+// for limits counted in bytes rather than characters such as IRC lines
+func TruncateBytes(text string, length int) string {
+	if len(text) <= length {
+		return text
+	}
+	if length <= len(ellipsis) {
+		return ""
+	}
+	text = text[:length-len(ellipsis)]
+	for !utf8.ValidString(text) {
+		text = text[:len(text)-1]
+	}
+	return text + ellipsis
+}
+
+// MessageOrTitle returns the message, or the title of the embed when there is nothing else to show.
+// Push services such as ntfy, Gotify and Pushover reject notifications without a message.
+func MessageOrTitle(message string, embed MessageEmbed) string {
+	if message == "" {
+		return embed.Title
+	}
+	return message
+}
@@ -0,0 +1,41 @@
+package output
+
+import "testing"
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		text     string
+		length   int
+		expected string
+	}{
+		{"short", 10, "short"},
+		{"exactly", 7, "exactly"},
+		{"too long", 5, "too …"},
+		{"ünïcödé", 4, "ünï…"},
+		{"anything", 1, ""},
+	}
+	for _, test := range tests {
+		if truncated := Truncate(test.text, test.length); truncated != test.expected {
+			t.Errorf("Truncate(%q, %d) = %q, expected %q", test.text, test.length, truncated, test.expected)
+		}
+	}
+}
+
+func TestTruncateBytes(t *testing.T) {
+	tests := []struct {
+		text     string
+		length   int
+		expected string
+	}{
+		{"short", 10, "short"},
+		{"too long", 7, "too …"},
+		{"ünïcödé", 8, "ünï…"},
+		{"ünïcödé", 7, "ün…"},
+		{"anything", 3, ""},
+	}
+	for _, test := range tests {
+		if truncated := TruncateBytes(test.text, test.length); truncated != test.expected {
+			t.Errorf("TruncateBytes(%q, %d) = %q, expected %q", test.text, test.length, truncated, test.expected)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package pushover
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"xenigo/internal/output"
+)
+
+// Pushover limits the length of the title, the message and the supplementary url
+const (
+	maxTitleLength   = 250
+	maxMessageLength = 1024
+	maxURLLength     = 512
+)
+
+type PushoverSender struct {
+	APIURL   string
+	AppToken string
+	UserKey  string // User or group key
+	Priority int    // -2 (lowest) to 1 (high)
+	Sound    string // Optional, the default sound of the user when empty
+	Device   string // Optional, all devices of the user when empty
+}
+
+type apiResponse struct {
+	Status int      `json:"status"`
+	Errors []string `json:"errors"`
+}
+
+func (p *PushoverSender) SendMessage(embed output.MessageEmbed) error {
+	log.Printf("Sending message to Pushover: %s", embed.Title)
+
+	form := url.Values{
+		"token":    {p.AppToken},
+		"user":     {p.UserKey},
+		"title":    {output.Truncate(embed.Title, maxTitleLength)},
+		"message":  {output.Truncate(formatMessage(embed), maxMessageLength)},
+		"priority": {strconv.Itoa(p.Priority)},
+	}
+	if embed.URL != "" && len(embed.URL) <= maxURLLength {
+		form.Set("url", embed.URL)
+		form.Set("url_title", "Open post")
+	}
+	if p.Sound != "" {
+		form.Set("sound", p.Sound)
+	}
+	if p.Device != "" {
+		form.Set("device", p.Device)
+	}
+
+	resp, err := http.PostForm(p.APIURL+"/1/messages.json", form)
+	if err != nil {
+		// The error contains the URL, but not the form with the keys
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result apiResponse
+	json.NewDecoder(resp.Body).Decode(&result)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("received non-200 response code: %d, %s", resp.StatusCode, strings.Join(result.Errors, ", "))
+	}
+	if result.Status != 1 {
+		return fmt.Errorf("message was not accepted: %s", strings.Join(result.Errors, ", "))
+	}
+	return nil
+}
+
+func formatMessage(embed output.MessageEmbed) string {
+	var lines []string
+	if embed.Author != "" {
+		lines = append(lines, "by u/"+embed.Author)
+	}
+	// Fields come first, the description is the part that gets truncated
+	for _, field := range embed.Fields {
+		lines = append(lines, fmt.Sprintf("%s: %s", field.Name, field.Value))
+	}
+	if embed.Description != "" && !embed.Spoiler {
+		lines = append(lines, "", embed.Description)
+	}
+	return output.MessageOrTitle(strings.Join(lines, "\n"), embed)
+}
@@ -0,0 +1,82 @@
+package pushover
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"unicode/utf8"
+	"xenigo/internal/output"
+)
+
+func TestSendMessage(t *testing.T) {
+	var form url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		form = r.PostForm
+		w.Write([]byte(`{"status":1,"request":"abc"}`))
+	}))
+	defer server.Close()
+
+	sender := &PushoverSender{APIURL: server.URL, AppToken: "app", UserKey: "user", Priority: 1, Sound: "cashregister", Device: "phone"}
+	err := sender.SendMessage(output.MessageEmbed{
+		Title:       strings.Repeat("t", 300),
+		Description: strings.Repeat("é", 2000),
+		URL:         "https://example.org/deal",
+		Author:      "someone",
+		Fields:      []output.EmbedField{{Name: "Price", Value: "1599 USD"}},
+	})
+	if err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+
+	expected := map[string]string{
+		"token":     "app",
+		"user":      "user",
+		"priority":  "1",
+		"sound":     "cashregister",
+		"device":    "phone",
+		"url":       "https://example.org/deal",
+		"url_title": "Open post",
+	}
+	for key, value := range expected {
+		if form.Get(key) != value {
+			t.Errorf("%s = %q, expected %q", key, form.Get(key), value)
+		}
+	}
+	if title := form.Get("title"); utf8.RuneCountInString(title) != maxTitleLength || !strings.HasSuffix(title, "…") {
+		t.Errorf("title of %d characters, expected it truncated to %d", utf8.RuneCountInString(title), maxTitleLength)
+	}
+	message := form.Get("message")
+	if utf8.RuneCountInString(message) != maxMessageLength || !strings.HasSuffix(message, "…") {
+		t.Errorf("message of %d characters, expected it truncated to %d", utf8.RuneCountInString(message), maxMessageLength)
+	}
+	// The fields come first, so only the description is cut off
+	if !strings.HasPrefix(message, "by u/someone\nPrice: 1599 USD\n\n") {
+		t.Errorf("message starts with %q", message[:40])
+	}
+}
+
+func TestSendMessageRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":0,"errors":["user identifier is invalid"]}`))
+	}))
+	defer server.Close()
+
+	sender := &PushoverSender{APIURL: server.URL, AppToken: "app", UserKey: "wrong"}
+	err := sender.SendMessage(output.MessageEmbed{Title: "A post"})
+	if err == nil || !strings.Contains(err.Error(), "user identifier is invalid") {
+		t.Errorf("SendMessage() error = %v, expected the errors returned by Pushover", err)
+	}
+}
+
+func TestFormatMessage(t *testing.T) {
+	// Pushover requires a message, so the title is used without any details
+	if message := formatMessage(output.MessageEmbed{Title: "A post"}); message != "A post" {
+		t.Errorf("formatMessage() = %q, expected the title", message)
+	}
+	if message := formatMessage(output.MessageEmbed{Title: "A post", Author: "someone", Description: "NSFW", Spoiler: true}); message != "by u/someone" {
+		t.Errorf("formatMessage() = %q, expected the NSFW description to be hidden", message)
+	}
+}
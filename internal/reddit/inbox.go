@@ -0,0 +1,87 @@
+package reddit
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"xenigo/internal/config"
+)
+
+const (
+	readMessageURL = oauthBaseURL + "/api/read_message"
+	messageURL     = "/message/messages/%s"
+)
+
+// Inbox kinds as returned by Reddit, comment replies and mentions use KindComment
+const (
+	KindMessage = "t4"
+)
+
+// buildInboxURL returns the endpoint for the inbox feeds of the authenticated account.
+func buildInboxURL(target config.Target) string {
+	query := url.Values{}
+	query.Set("limit", strconv.Itoa(target.Options.Limit))
+	// Never let Reddit mark messages as read when fetching, this is controlled by mark_read
+	query.Set("mark", "false")
+	return fmt.Sprintf("%s/message/%s?%s", oauthBaseURL, target.Monitor.Inbox, query.Encode())
+}
+
+// normalizeInboxChild maps private messages, comment replies and mentions onto the post fields.
+// Inbox items have no permalink of their own, so they are keyed by their fullname.
+func normalizeInboxChild(child RedditChild) RedditChild {
+	post := &child.Data
+	post.key = post.Name
+	post.Selftext = post.Body
+
+	if child.Kind == KindComment {
+		post.Title = fmt.Sprintf("%s: %s", post.Subject, post.LinkTitle)
+		post.Permalink = post.Context
+	} else {
+		post.Title = post.Subject
+		post.Permalink = fmt.Sprintf(messageURL, post.ID)
+	}
+	post.URL = publicBaseURL + post.Permalink
+	return child
+}
+
+// MarkMessagesRead marks the given inbox items, identified by fullname, as read.
+// The access token is refreshed and the request retried once when it expired.
+func MarkMessagesRead(names []string, accessToken string, userAgent string, oauthConfig *config.OAuthConfig) error {
+	if len(names) == 0 {
+		return nil
+	}
+
+	data := url.Values{}
+	data.Set("id", strings.Join(names, ","))
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequest("POST", readMessageURL, strings.NewReader(data.Encode()))
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", latestToken(accessToken)))
+		req.Header.Set("User-Agent", userAgent)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to execute request: %w", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode == http.StatusUnauthorized && attempt == 0 {
+			if err := renewAccessToken(oauthConfig); err != nil {
+				return err
+			}
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("received non-200 response code: %d", resp.StatusCode)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,92 @@
+package reddit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"xenigo/internal/config"
+)
+
+func TestNormalizeInboxChild(t *testing.T) {
+	message := normalizeInboxChild(RedditChild{Kind: KindMessage, Data: RedditPost{
+		ID:      "2ab3c",
+		Name:    "t4_2ab3c",
+		Subject: "Is the 4090 still available?",
+		Body:    "I can pay today.",
+	}})
+	if message.Data.Title != "Is the 4090 still available?" || message.Data.Selftext != "I can pay today." {
+		t.Errorf("unexpected message %+v", message.Data)
+	}
+	if message.Data.URL != "https://www.reddit.com/message/messages/2ab3c" || message.Data.Key() != "t4_2ab3c" {
+		t.Errorf("unexpected link %q or key %q", message.Data.URL, message.Data.Key())
+	}
+
+	mention := normalizeInboxChild(RedditChild{Kind: KindComment, Data: RedditPost{
+		Name:      "t1_def",
+		Subject:   "username mention",
+		LinkTitle: "Best GPU for the money?",
+		Body:      "Ask u/gpu_expert",
+		Context:   "/r/buildapc/comments/abc/title/def/?context=3",
+		Subreddit: "buildapc",
+	}})
+	if mention.Data.Title != "username mention: Best GPU for the money?" || mention.Data.Selftext != "Ask u/gpu_expert" {
+		t.Errorf("unexpected mention %+v", mention.Data)
+	}
+	if mention.Data.URL != "https://www.reddit.com/r/buildapc/comments/abc/title/def/?context=3" || mention.Data.Key() != "t1_def" {
+		t.Errorf("unexpected link %q or key %q", mention.Data.URL, mention.Data.Key())
+	}
+}
+
+// redirectTransport sends every request to the test server, keeping the path
+type redirectTransport struct {
+	host      string
+	transport http.RoundTripper
+}
+
+func (r redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.URL.Scheme, req.URL.Host = "http", r.host
+	return r.transport.RoundTrip(req)
+}
+
+func TestMarkMessagesReadRefreshesToken(t *testing.T) {
+	var authorizations []string
+	var ids string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/access_token":
+			w.Write([]byte(`{"access_token": "fresh"}`))
+		case "/api/read_message":
+			authorizations = append(authorizations, r.Header.Get("Authorization"))
+			if r.Header.Get("Authorization") != "Bearer fresh" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			r.ParseForm()
+			ids = r.PostForm.Get("id")
+		}
+	}))
+	defer server.Close()
+
+	transport := http.DefaultTransport
+	http.DefaultTransport = redirectTransport{server.Listener.Addr().String(), transport}
+	defer func() {
+		http.DefaultTransport = transport
+		currentToken, lastRefreshTime = "", time.Time{}
+	}()
+
+	err := MarkMessagesRead([]string{"t4_a", "t1_b"}, "expired", "xenigo", &config.OAuthConfig{})
+	if err != nil {
+		t.Fatalf("MarkMessagesRead() error = %v", err)
+	}
+	if strings.Join(authorizations, ",") != "Bearer expired,Bearer fresh" {
+		t.Errorf("authorizations = %q, expected a retry with the refreshed token", authorizations)
+	}
+	if ids != "t4_a,t1_b" {
+		t.Errorf("id = %q", ids)
+	}
+	if latestToken("expired") != "fresh" {
+		t.Error("refreshed token was not kept for later requests")
+	}
+}
@@ -0,0 +1,61 @@
+package reddit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"xenigo/internal/config"
+)
+
+// Reddit accepts at most 100 fullnames per /api/info request
+const maxInfoIDs = 100
+
+// FetchPostsInfo fetches the current state of the given posts, identified by fullname, via /api/info.
+func FetchPostsInfo(names []string, options *config.Options, accessToken string, userAgent string, context string, oauthConfig *config.OAuthConfig) ([]RedditPost, error) {
+	baseURL, suffix := publicBaseURL, ".json"
+	if context == "elevated" {
+		baseURL, suffix = oauthBaseURL, ""
+	}
+
+	var posts []RedditPost
+	for start := 0; start < len(names); start += maxInfoIDs {
+		end := start + maxInfoIDs
+		if end > len(names) {
+			end = len(names)
+		}
+
+		query := url.Values{}
+		query.Set("id", strings.Join(names[start:end], ","))
+		requestURL := fmt.Sprintf("%s/api/info%s?%s", baseURL, suffix, query.Encode())
+
+		var redditResponse RedditResponse
+		err := fetchWithRetry(requestURL, options, accessToken, userAgent, context, oauthConfig, func(body io.Reader) error {
+			return json.NewDecoder(body).Decode(&redditResponse)
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, child := range redditResponse.Data.Children {
+			posts = append(posts, child.Data)
+		}
+	}
+	return posts, nil
+}
+
+// IsDeleted reports whether the post was deleted by its author.
+func (p RedditPost) IsDeleted() bool {
+	if p.RemovedByCategory != nil && *p.RemovedByCategory == "deleted" {
+		return true
+	}
+	return p.Author == "[deleted]" || p.Selftext == "[deleted]"
+}
+
+// IsRemoved reports whether the post was removed by the moderators, AutoModerator or Reddit.
+func (p RedditPost) IsRemoved() bool {
+	if p.IsDeleted() {
+		return false
+	}
+	return (p.RemovedByCategory != nil && *p.RemovedByCategory != "") || p.Selftext == "[removed]"
+}
@@ -0,0 +1,190 @@
+package reddit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"xenigo/internal/config"
+)
+
+const modmailURL = "https://mod.reddit.com/mail/all/%s"
+
+// ModLogResponse is the listing returned by /r/{subreddit}/about/log
+type ModLogResponse struct {
+	Data struct {
+		Children []struct {
+			Kind string    `json:"kind"`
+			Data ModAction `json:"data"`
+		} `json:"children"`
+	} `json:"data"`
+}
+
+type ModAction struct {
+	ID              string `json:"id"`
+	Action          string `json:"action"`
+	Mod             string `json:"mod"`
+	Details         string `json:"details"`
+	Description     string `json:"description"`
+	Subreddit       string `json:"subreddit"`
+	TargetAuthor    string `json:"target_author"`
+	TargetTitle     string `json:"target_title"`
+	TargetBody      string `json:"target_body"`
+	TargetPermalink string `json:"target_permalink"`
+}
+
+// ModmailResponse is returned by /api/mod/conversations, conversations and messages are keyed by id
+type ModmailResponse struct {
+	Conversations   map[string]ModmailConversation `json:"conversations"`
+	ConversationIDs []string                       `json:"conversationIds"`
+	Messages        map[string]ModmailMessage      `json:"messages"`
+}
+
+type ModmailConversation struct {
+	ID      string `json:"id"`
+	Subject string `json:"subject"`
+	Owner   struct {
+		DisplayName string `json:"displayName"`
+	} `json:"owner"`
+	Authors []struct {
+		Name string `json:"name"`
+	} `json:"authors"`
+	ObjIDs []struct {
+		ID  string `json:"id"`
+		Key string `json:"key"`
+	} `json:"objIds"`
+}
+
+type ModmailMessage struct {
+	ID           string `json:"id"`
+	BodyMarkdown string `json:"bodyMarkdown"`
+	Author       struct {
+		Name string `json:"name"`
+	} `json:"author"`
+}
+
+// buildModerationURL returns the endpoint for the moderator-only feeds, these are only available over OAuth.
+func buildModerationURL(target config.Target) string {
+	query := url.Values{}
+	query.Set("limit", strconv.Itoa(target.Options.Limit))
+
+	if target.Monitor.Moderation == config.ModerationModmail {
+		query.Set("entity", target.Monitor.Subreddit)
+		query.Set("state", "new")
+		query.Set("sort", "recent")
+		return fmt.Sprintf("%s/api/mod/conversations?%s", oauthBaseURL, query.Encode())
+	}
+	return fmt.Sprintf("%s/r/%s/about/%s?%s", oauthBaseURL, target.Monitor.Subreddit, target.Monitor.Moderation, query.Encode())
+}
+
+// decodeResponse decodes the response body for the given target into the common listing shape.
+func decodeResponse(target config.Target, body io.Reader) (*RedditResponse, error) {
+	switch target.Monitor.Moderation {
+	case config.ModerationLog:
+		return decodeModLog(body)
+	case config.ModerationModmail:
+		return decodeModmail(body)
+	}
+
+	var redditResponse RedditResponse
+	if err := json.NewDecoder(body).Decode(&redditResponse); err != nil {
+		return nil, err
+	}
+	return &redditResponse, nil
+}
+
+func decodeModLog(body io.Reader) (*RedditResponse, error) {
+	var modLog ModLogResponse
+	if err := json.NewDecoder(body).Decode(&modLog); err != nil {
+		return nil, err
+	}
+
+	var redditResponse RedditResponse
+	for _, child := range modLog.Data.Children {
+		redditResponse.Data.Children = append(redditResponse.Data.Children, RedditChild{
+			Kind: child.Kind,
+			Data: child.Data.toPost(),
+		})
+	}
+	return &redditResponse, nil
+}
+
+func (a ModAction) toPost() RedditPost {
+	title := fmt.Sprintf("%s: %s", a.Mod, a.Action)
+	if a.TargetAuthor != "" {
+		title = fmt.Sprintf("%s on u/%s", title, a.TargetAuthor)
+	}
+
+	var details []string
+	for _, detail := range []string{a.Details, a.Description, a.TargetTitle, a.TargetBody} {
+		if detail != "" {
+			details = append(details, detail)
+		}
+	}
+
+	post := RedditPost{
+		Title:     title,
+		Author:    a.Mod,
+		Selftext:  strings.Join(details, "\n"),
+		Subreddit: a.Subreddit,
+		Permalink: a.TargetPermalink,
+		Name:      a.ID,
+		key:       a.ID,
+	}
+	if a.TargetPermalink != "" {
+		post.URL = publicBaseURL + a.TargetPermalink
+	}
+	return post
+}
+
+func decodeModmail(body io.Reader) (*RedditResponse, error) {
+	var modmail ModmailResponse
+	if err := json.NewDecoder(body).Decode(&modmail); err != nil {
+		return nil, err
+	}
+
+	var redditResponse RedditResponse
+	for _, id := range modmail.ConversationIDs {
+		conversation, ok := modmail.Conversations[id]
+		if !ok {
+			continue
+		}
+		redditResponse.Data.Children = append(redditResponse.Data.Children, RedditChild{
+			Kind: "modmail",
+			Data: conversation.toPost(modmail.Messages),
+		})
+	}
+	return &redditResponse, nil
+}
+
+func (c ModmailConversation) toPost(messages map[string]ModmailMessage) RedditPost {
+	link := fmt.Sprintf(modmailURL, c.ID)
+	post := RedditPost{
+		Title:     c.Subject,
+		URL:       link,
+		Permalink: link,
+		Subreddit: c.Owner.DisplayName,
+		Name:      c.ID,
+		key:       "modmail_" + c.ID,
+	}
+	if len(c.Authors) > 0 {
+		post.Author = c.Authors[0].Name
+	}
+
+	// Show the latest message of the conversation
+	for i := len(c.ObjIDs) - 1; i >= 0; i-- {
+		if c.ObjIDs[i].Key != "messages" {
+			continue
+		}
+		if message, ok := messages[c.ObjIDs[i].ID]; ok {
+			post.Selftext = message.BodyMarkdown
+			if post.Author == "" {
+				post.Author = message.Author.Name
+			}
+		}
+		break
+	}
+	return post
+}
@@ -0,0 +1,164 @@
+package reddit
+
+import (
+	"strings"
+	"testing"
+	"xenigo/internal/config"
+)
+
+func TestBuildModerationURL(t *testing.T) {
+	tests := []struct {
+		feed     config.ModerationFeed
+		expected string
+	}{
+		{config.ModerationModqueue, "https://oauth.reddit.com/r/golang/about/modqueue?limit=25"},
+		{config.ModerationReports, "https://oauth.reddit.com/r/golang/about/reports?limit=25"},
+		{config.ModerationSpam, "https://oauth.reddit.com/r/golang/about/spam?limit=25"},
+		{config.ModerationLog, "https://oauth.reddit.com/r/golang/about/log?limit=25"},
+		{config.ModerationModmail, "https://oauth.reddit.com/api/mod/conversations?entity=golang&limit=25&sort=recent&state=new"},
+	}
+	for _, test := range tests {
+		target := config.Target{
+			Monitor: config.Monitor{Subreddit: "golang", Moderation: test.feed},
+			Options: &config.Options{Limit: 25},
+		}
+		if requestURL := buildModerationURL(target); requestURL != test.expected {
+			t.Errorf("buildModerationURL(%s) = %q, expected %q", test.feed, requestURL, test.expected)
+		}
+	}
+}
+
+// Trimmed response of /r/golang/about/log
+const modLogSample = `{
+  "kind": "Listing",
+  "data": {
+    "after": null,
+    "children": [
+      {
+        "kind": "modaction",
+        "data": {
+          "id": "ModAction_5e7c1f2a-0b1d-11ef-9a3e-2a7b5c9e1d00",
+          "action": "removelink",
+          "mod": "gopher_mod",
+          "details": "remove",
+          "description": null,
+          "subreddit": "golang",
+          "target_author": "spammer_1",
+          "target_title": "Cheap watches",
+          "target_body": null,
+          "target_permalink": "/r/golang/comments/1cfx2a/cheap_watches/",
+          "created_utc": 1714464000.0
+        }
+      },
+      {
+        "kind": "modaction",
+        "data": {
+          "id": "ModAction_6a1b2c3d-0b1d-11ef-9a3e-2a7b5c9e1d00",
+          "action": "editsettings",
+          "mod": "gopher_mod",
+          "details": "description",
+          "subreddit": "golang",
+          "target_author": "",
+          "target_permalink": null,
+          "created_utc": 1714464100.0
+        }
+      }
+    ]
+  }
+}`
+
+func TestDecodeModLog(t *testing.T) {
+	response, err := decodeResponse(config.Target{Monitor: config.Monitor{Moderation: config.ModerationLog}}, strings.NewReader(modLogSample))
+	if err != nil {
+		t.Fatalf("decodeResponse() error = %v", err)
+	}
+	if len(response.Data.Children) != 2 {
+		t.Fatalf("decoded %d actions, expected 2", len(response.Data.Children))
+	}
+
+	removal := response.Data.Children[0].Data
+	if removal.Title != "gopher_mod: removelink on u/spammer_1" {
+		t.Errorf("Title = %q", removal.Title)
+	}
+	if removal.Selftext != "remove\nCheap watches" {
+		t.Errorf("Selftext = %q", removal.Selftext)
+	}
+	if removal.URL != "https://www.reddit.com/r/golang/comments/1cfx2a/cheap_watches/" {
+		t.Errorf("URL = %q", removal.URL)
+	}
+	if removal.Key() != "ModAction_5e7c1f2a-0b1d-11ef-9a3e-2a7b5c9e1d00" {
+		t.Errorf("Key() = %q, expected the action id", removal.Key())
+	}
+
+	settings := response.Data.Children[1].Data
+	if settings.Title != "gopher_mod: editsettings" || settings.URL != "" {
+		t.Errorf("unexpected action without target %+v", settings)
+	}
+	if settings.Key() == removal.Key() {
+		t.Error("actions without permalink share a key")
+	}
+}
+
+// Trimmed response of /api/mod/conversations, the last id has no conversation and the second
+// conversation has neither authors nor messages
+const modmailSample = `{
+  "conversations": {
+    "2b8x1": {
+      "id": "2b8x1",
+      "subject": "Why was my post removed?",
+      "owner": {"displayName": "golang", "type": "subreddit", "id": "t5_2rc7j"},
+      "authors": [
+        {"name": "new_gopher", "isMod": false},
+        {"name": "gopher_mod", "isMod": true}
+      ],
+      "objIds": [
+        {"id": "4kq9a", "key": "messages"},
+        {"id": "4kq9b", "key": "messages"},
+        {"id": "a1", "key": "modActions"}
+      ]
+    },
+    "2b8x2": {
+      "id": "2b8x2",
+      "subject": "Invitation to moderate",
+      "owner": {"displayName": "golang"},
+      "objIds": [
+        {"id": "4kq9c", "key": "messages"}
+      ]
+    }
+  },
+  "conversationIds": ["2b8x1", "2b8x2", "2b8x3"],
+  "messages": {
+    "4kq9a": {"id": "4kq9a", "bodyMarkdown": "My post about generics was removed.", "author": {"name": "new_gopher"}},
+    "4kq9b": {"id": "4kq9b", "bodyMarkdown": "It was a duplicate, see rule 3.", "author": {"name": "gopher_mod"}}
+  }
+}`
+
+func TestDecodeModmail(t *testing.T) {
+	response, err := decodeResponse(config.Target{Monitor: config.Monitor{Moderation: config.ModerationModmail}}, strings.NewReader(modmailSample))
+	if err != nil {
+		t.Fatalf("decodeResponse() error = %v", err)
+	}
+	if len(response.Data.Children) != 2 {
+		t.Fatalf("decoded %d conversations, expected 2", len(response.Data.Children))
+	}
+
+	conversation := response.Data.Children[0].Data
+	if conversation.Title != "Why was my post removed?" || conversation.Subreddit != "golang" || conversation.Author != "new_gopher" {
+		t.Errorf("unexpected conversation %+v", conversation)
+	}
+	if conversation.Selftext != "It was a duplicate, see rule 3." {
+		t.Errorf("Selftext = %q, expected the latest message", conversation.Selftext)
+	}
+	if conversation.URL != "https://mod.reddit.com/mail/all/2b8x1" || conversation.Key() != "modmail_2b8x1" {
+		t.Errorf("unexpected link %q or key %q", conversation.URL, conversation.Key())
+	}
+
+	incomplete := response.Data.Children[1].Data
+	if incomplete.Title != "Invitation to moderate" || incomplete.Author != "" || incomplete.Selftext != "" {
+		t.Errorf("unexpected conversation without authors or messages %+v", incomplete)
+	}
+
+	if _, err := decodeModmail(strings.NewReader(`{}`)); err != nil {
+		t.Errorf("decodeModmail() of an empty response error = %v", err)
+	}
+}
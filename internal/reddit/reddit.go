@@ -7,6 +7,7 @@ import (
     "log"
     "net/http"
     "net/url"
+    "strconv"
     "strings"
     "sync"
     "time"
@@ -15,8 +16,8 @@ import (
 
 const (
     tokenURL        = "https://www.reddit.com/api/v1/access_token"
-    apiURL          = "https://oauth.reddit.com/r/%s/%s"
-    jsonAPIURL      = "https://www.reddit.com/r/%s/%s.json"
+    oauthBaseURL    = "https://oauth.reddit.com"
+    publicBaseURL   = "https://www.reddit.com"
     defaultRetryCount = 3
     defaultRetryInterval = 2
     defaultRetryIntervalSeconds = 2 * time.Second
@@ -24,12 +25,21 @@ const (
 
 type RedditResponse struct {
     Data struct {
-        Children []struct {
-            Data RedditPost `json:"data"`
-        } `json:"children"`
+        Children []RedditChild `json:"children"`
     } `json:"data"`
 }
 
+type RedditChild struct {
+    Kind string     `json:"kind"`
+    Data RedditPost `json:"data"`
+}
+
+// Listing kinds as returned by Reddit
+const (
+    KindComment = "t1"
+    KindLink    = "t3"
+)
+
 type RedditPost struct {
     Title       string `json:"title"`
     URL         string `json:"url"`
@@ -37,14 +47,119 @@ type RedditPost struct {
     Permalink   string `json:"permalink"`
     Selftext    string `json:"selftext"`
     Stickied    bool   `json:"stickied"`
+    Subreddit   string `json:"subreddit"`
+    Name        string `json:"name"`
+    LinkFlairText     string  `json:"link_flair_text"`
+    RemovedByCategory *string `json:"removed_by_category"`
+    Over18          bool   `json:"over_18"`
+    Spoiler         bool   `json:"spoiler"`
+    CrosspostParent string `json:"crosspost_parent"`
+    PostHint        string `json:"post_hint"`
+    Score       int     `json:"score"`
+    NumComments int     `json:"num_comments"`
+    CreatedUTC  float64 `json:"created_utc"`
+
+    // Only set for comments
+    Body        string `json:"body"`
+    LinkTitle   string `json:"link_title"`
+
+    // Only set for inbox items
+    ID          string `json:"id"`
+    Subject     string `json:"subject"`
+    Context     string `json:"context"`
+
+    // Only set in the moderation queues
+    NumReports  int             `json:"num_reports"`
+    UserReports [][]interface{} `json:"user_reports"`
+    ModReports  [][]interface{} `json:"mod_reports"`
+
+    // Overrides the permalink as the deduplication key for items without a stable permalink
+    key string
+}
+
+// Key returns the key used to deduplicate the post.
+func (p RedditPost) Key() string {
+    if p.key != "" {
+        return p.key
+    }
+    return p.Permalink
+}
+
+// DiscussionURL returns the full link to the post on Reddit.
+func (p RedditPost) DiscussionURL() string {
+    if strings.HasPrefix(p.Permalink, "https://") {
+        return p.Permalink
+    }
+    return publicBaseURL + p.Permalink
+}
+
+// ImageURL returns the link of the post when it points directly to an image.
+func (p RedditPost) ImageURL() string {
+    if p.PostHint == "image" {
+        return p.URL
+    }
+    path := strings.ToLower(p.URL)
+    if i := strings.IndexAny(path, "?#"); i >= 0 {
+        path = path[:i]
+    }
+    for _, extension := range []string{".jpg", ".jpeg", ".png", ".gif", ".webp"} {
+        if strings.HasSuffix(path, extension) {
+            return p.URL
+        }
+    }
+    return ""
+}
+
+// ReportReasons returns the user and moderator report reasons of a post in the moderation queues.
+func (p RedditPost) ReportReasons() []string {
+    var reasons []string
+    for _, report := range append(p.UserReports, p.ModReports...) {
+        if len(report) > 0 {
+            if reason, ok := report[0].(string); ok && reason != "" {
+                reasons = append(reasons, reason)
+            }
+        }
+    }
+    return reasons
 }
 
 var (
     tokenMutex      sync.Mutex
     lastRefreshTime time.Time
     refreshInterval = 1 * time.Minute // Set the minimum interval between token refreshes
+    currentToken    string            // Replaces the token obtained at startup once it was refreshed
 )
 
+// latestToken returns the most recently refreshed access token, or the given one when it was never refreshed
+func latestToken(accessToken string) string {
+    tokenMutex.Lock()
+    defer tokenMutex.Unlock()
+    if currentToken != "" {
+        return currentToken
+    }
+    return accessToken
+}
+
+// renewAccessToken refreshes the access token shared by all requests after a 401 response.
+// When it was refreshed recently, it waits for the refresh interval to pass instead.
+func renewAccessToken(oauthConfig *config.OAuthConfig) error {
+    tokenMutex.Lock()
+    if since := time.Since(lastRefreshTime); since < refreshInterval {
+        tokenMutex.Unlock()
+        log.Println("Token was recently refreshed, waiting before retrying...")
+        time.Sleep(refreshInterval - since)
+        return nil
+    }
+    defer tokenMutex.Unlock()
+    token, err := refreshAccessToken(oauthConfig)
+    lastRefreshTime = time.Now()
+    if err != nil {
+        return err
+    }
+    currentToken = token
+    return nil
+}
+
 func GetAccessToken(oauthConfig *config.OAuthConfig) (string, error) {
     data := url.Values{}
     data.Set("grant_type", "password")
@@ -94,31 +209,61 @@ func refreshAccessToken(oauthConfig *config.OAuthConfig) (string, error) {
 }
 
 func FetchRedditData(target config.Target, accessToken string, userAgent string, context string, oauthConfig *config.OAuthConfig) (*RedditResponse, error) {
+    requestURL := buildListingURL(target, context)
+    if target.Monitor.Moderation != "" {
+        requestURL = buildModerationURL(target)
+    }
+    if target.Monitor.Inbox != "" {
+        requestURL = buildInboxURL(target)
+    }
+
+    var redditResponse *RedditResponse
+    err := fetchWithRetry(requestURL, target.Options, accessToken, userAgent, context, oauthConfig, func(body io.Reader) error {
+        var err error
+        redditResponse, err = decodeResponse(target, body)
+        return err
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    // Filter out pinned modposts, NSFW, spoilers and crossposts as configured
+    filteredChildren := []RedditChild{}
+    for _, child := range redditResponse.Data.Children {
+        if !includePost(child.Data, target.Content) {
+            continue
+        }
+        if target.Monitor.Inbox != "" {
+            filteredChildren = append(filteredChildren, normalizeInboxChild(child))
+        } else {
+            filteredChildren = append(filteredChildren, normalizeChild(child))
+        }
+    }
+    redditResponse.Data.Children = filteredChildren
+    return redditResponse, nil
+}
+
+// fetchWithRetry performs a GET request against the Reddit API, refreshing the access token and
+// retrying as configured, and hands the body of the successful response to decode.
+func fetchWithRetry(requestURL string, options *config.Options, accessToken string, userAgent string, context string, oauthConfig *config.OAuthConfig, decode func(body io.Reader) error) error {
     client := &http.Client{
         Timeout: 10 * time.Second, // Set a timeout for the HTTP client
     }
-    url := fmt.Sprintf(jsonAPIURL, target.Monitor.Subreddit, target.Monitor.Sorting)
-    if context == "elevated" {
-        url = fmt.Sprintf(apiURL, target.Monitor.Subreddit, target.Monitor.Sorting)
-    }
-    // Add limit parameter to the URL
-    url = fmt.Sprintf("%s?limit=%d", url, target.Options.Limit)
-    var redditResponse RedditResponse
-    retries := target.Options.RetryCount
+    retries := options.RetryCount
     if retries == 0 {
         retries = defaultRetryCount // Default retry count
     }
-    retryInterval := target.Options.RetryInterval
+    retryInterval := options.RetryInterval
     if retryInterval == 0 {
         retryInterval = defaultRetryInterval // Default retry interval
     }
     for i := 0; i < retries; i++ {
-        req, err := http.NewRequest("GET", url, nil)
+        req, err := http.NewRequest("GET", requestURL, nil)
         if err != nil {
-            return nil, fmt.Errorf("failed to create request: %w", err)
+            return fmt.Errorf("failed to create request: %w", err)
         }
         if context == "elevated" {
-            req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
+            req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", latestToken(accessToken)))
         }
         req.Header.Set("User-Agent", userAgent)
         resp, err := client.Do(req)
@@ -130,18 +275,8 @@ func FetchRedditData(target config.Target, accessToken string, userAgent string,
         defer resp.Body.Close()
         if resp.StatusCode == http.StatusUnauthorized {
             // Refresh the access token and retry
-            tokenMutex.Lock()
-            if time.Since(lastRefreshTime) < refreshInterval {
-                tokenMutex.Unlock()
-                log.Println("Token was recently refreshed, waiting before retrying...")
-                time.Sleep(refreshInterval - time.Since(lastRefreshTime))
-                continue
-            }
-            accessToken, err = refreshAccessToken(oauthConfig)
-            lastRefreshTime = time.Now()
-            tokenMutex.Unlock()
-            if err != nil {
-                return nil, err
+            if err := renewAccessToken(oauthConfig); err != nil {
+                return err
             }
             continue
         }
@@ -149,22 +284,75 @@ func FetchRedditData(target config.Target, accessToken string, userAgent string,
             bodyBytes, _ := io.ReadAll(resp.Body)
             bodyString := string(bodyBytes)
             log.Printf("Error: received non-200 response code: %d, body: %s", resp.StatusCode, bodyString)
-            return nil, fmt.Errorf("received non-200 response code: %d", resp.StatusCode)
+            return fmt.Errorf("received non-200 response code: %d", resp.StatusCode)
         }
-        if err := json.NewDecoder(resp.Body).Decode(&redditResponse); err != nil {
-            return nil, fmt.Errorf("failed to decode response: %w", err)
+        if err := decode(resp.Body); err != nil {
+            return fmt.Errorf("failed to decode response: %w", err)
         }
-        // Filter out pinned modposts
-        filteredChildren := []struct {
-            Data RedditPost `json:"data"`
-        }{}
-        for _, child := range redditResponse.Data.Children {
-            if !child.Data.Stickied {
-                filteredChildren = append(filteredChildren, child)
-            }
-        }
-        redditResponse.Data.Children = filteredChildren
-        return &redditResponse, nil
+        return nil
     }
-    return nil, fmt.Errorf("failed to fetch Reddit data after %d attempts", retries)
-}
\ No newline at end of file
+    return fmt.Errorf("failed to fetch Reddit data after %d attempts", retries)
+}
+
+// includePost applies the stickied, NSFW, spoiler and crosspost options of the target to the post.
+func includePost(post RedditPost, content *config.Content) bool {
+    if content == nil {
+        return !post.Stickied
+    }
+    return matchesContentFilter(content.Stickied, post.Stickied) &&
+        matchesContentFilter(content.NSFW, post.Over18) &&
+        matchesContentFilter(content.Spoiler, post.Spoiler) &&
+        matchesContentFilter(content.Crossposts, post.CrosspostParent != "")
+}
+
+func matchesContentFilter(filter config.ContentFilter, value bool) bool {
+    switch filter {
+    case config.ContentExclude:
+        return !value
+    case config.ContentOnly:
+        return value
+    default:
+        return true
+    }
+}
+
+// buildListingURL returns the listing endpoint for the monitored subreddit, user feed or multireddit.
+func buildListingURL(target config.Target, context string) string {
+    baseURL, suffix := publicBaseURL, ".json"
+    if context == "elevated" {
+        baseURL, suffix = oauthBaseURL, ""
+    }
+
+    query := url.Values{}
+    query.Set("limit", strconv.Itoa(target.Options.Limit))
+    if target.Monitor.TimeFilter != "" {
+        query.Set("t", target.Monitor.TimeFilter)
+    }
+
+    var path string
+    if user := target.Monitor.User; user != nil {
+        // User feeds take the sorting as a query parameter rather than a path segment
+        path = fmt.Sprintf("/user/%s/%s", url.PathEscape(user.Name), user.Feed)
+        query.Set("sort", target.Monitor.Sorting)
+    } else if multi := target.Monitor.Multireddit; multi != nil {
+        path = fmt.Sprintf("/user/%s/m/%s/%s", url.PathEscape(multi.User), url.PathEscape(multi.Name), target.Monitor.Sorting)
+    } else {
+        // Combined subreddits such as "a+b+c" are passed through as-is
+        path = fmt.Sprintf("/r/%s/%s", target.Monitor.Subreddit, target.Monitor.Sorting)
+    }
+
+    return fmt.Sprintf("%s%s%s?%s", baseURL, path, suffix, query.Encode())
+}
+
+// normalizeChild maps comments onto the post fields used by the notifiers,
+// so that comment feeds can share the same pipeline as link posts.
+func normalizeChild(child RedditChild) RedditChild {
+    if child.Kind != KindComment {
+        return child
+    }
+    post := &child.Data
+    post.Title = fmt.Sprintf("Comment on: %s", post.LinkTitle)
+    post.Selftext = post.Body
+    post.URL = publicBaseURL + post.Permalink
+    return child
+}
@@ -0,0 +1,118 @@
+package reddit
+
+import (
+	"reflect"
+	"testing"
+	"xenigo/internal/config"
+)
+
+func TestBuildListingURLUserFeeds(t *testing.T) {
+	tests := []struct {
+		feed     config.UserFeed
+		context  string
+		expected string
+	}{
+		{config.UserFeedSubmitted, "", "https://www.reddit.com/user/spez/submitted.json?limit=5&sort=new"},
+		{config.UserFeedComments, "", "https://www.reddit.com/user/spez/comments.json?limit=5&sort=new"},
+		{config.UserFeedOverview, "", "https://www.reddit.com/user/spez/overview.json?limit=5&sort=new"},
+		{config.UserFeedSubmitted, "elevated", "https://oauth.reddit.com/user/spez/submitted?limit=5&sort=new"},
+	}
+	for _, test := range tests {
+		target := config.Target{
+			Monitor: config.Monitor{User: &config.UserMonitor{Name: "spez", Feed: test.feed}, Sorting: "new"},
+			Options: &config.Options{Limit: 5},
+		}
+		if requestURL := buildListingURL(target, test.context); requestURL != test.expected {
+			t.Errorf("buildListingURL(%s, %q) = %q, expected %q", test.feed, test.context, requestURL, test.expected)
+		}
+	}
+}
+
+func TestNormalizeChild(t *testing.T) {
+	comment := normalizeChild(RedditChild{Kind: KindComment, Data: RedditPost{
+		Body:      "Still available?",
+		LinkTitle: "[H] 4090 [W] PayPal",
+		Permalink: "/r/hardwareswap/comments/abc/title/def/",
+	}})
+	if comment.Data.Title != "Comment on: [H] 4090 [W] PayPal" {
+		t.Errorf("Title = %q", comment.Data.Title)
+	}
+	if comment.Data.Selftext != "Still available?" {
+		t.Errorf("Selftext = %q, expected the comment body", comment.Data.Selftext)
+	}
+	if comment.Data.URL != "https://www.reddit.com/r/hardwareswap/comments/abc/title/def/" {
+		t.Errorf("URL = %q, expected the comment permalink", comment.Data.URL)
+	}
+
+	link := RedditChild{Kind: KindLink, Data: RedditPost{Title: "A post", URL: "https://example.org"}}
+	if normalized := normalizeChild(link); !reflect.DeepEqual(normalized, link) {
+		t.Errorf("normalizeChild() changed a link post to %+v", normalized.Data)
+	}
+}
+
+func TestBuildListingURLMultireddit(t *testing.T) {
+	target := config.Target{
+		Monitor: config.Monitor{Multireddit: &config.MultiredditMonitor{User: "spez", Name: "tech deals"}, Sorting: "hot"},
+		Options: &config.Options{Limit: 10},
+	}
+	if requestURL := buildListingURL(target, ""); requestURL != "https://www.reddit.com/user/spez/m/tech%20deals/hot.json?limit=10" {
+		t.Errorf("buildListingURL() = %q", requestURL)
+	}
+	if requestURL := buildListingURL(target, "elevated"); requestURL != "https://oauth.reddit.com/user/spez/m/tech%20deals/hot?limit=10" {
+		t.Errorf("buildListingURL() = %q", requestURL)
+	}
+}
+
+func TestBuildListingURLSorting(t *testing.T) {
+	tests := []struct {
+		monitor  config.Monitor
+		expected string
+	}{
+		{config.Monitor{Subreddit: "golang", Sorting: "new"}, "https://www.reddit.com/r/golang/new.json?limit=5"},
+		{config.Monitor{Subreddit: "golang", Sorting: "top"}, "https://www.reddit.com/r/golang/top.json?limit=5"},
+		{config.Monitor{Subreddit: "golang", Sorting: "top", TimeFilter: "week"}, "https://www.reddit.com/r/golang/top.json?limit=5&t=week"},
+		{config.Monitor{Subreddit: "golang+rust", Sorting: "controversial"}, "https://www.reddit.com/r/golang+rust/controversial.json?limit=5"},
+		{config.Monitor{Subreddit: "golang+rust", Sorting: "controversial", TimeFilter: "all"}, "https://www.reddit.com/r/golang+rust/controversial.json?limit=5&t=all"},
+		{config.Monitor{User: &config.UserMonitor{Name: "spez", Feed: config.UserFeedSubmitted}, Sorting: "top", TimeFilter: "day"}, "https://www.reddit.com/user/spez/submitted.json?limit=5&sort=top&t=day"},
+	}
+	for _, test := range tests {
+		target := config.Target{Monitor: test.monitor, Options: &config.Options{Limit: 5}}
+		if requestURL := buildListingURL(target, ""); requestURL != test.expected {
+			t.Errorf("buildListingURL(%s, %s, %q) = %q, expected %q", test.monitor, test.monitor.Sorting, test.monitor.TimeFilter, requestURL, test.expected)
+		}
+	}
+}
+
+func TestIncludePost(t *testing.T) {
+	stickied := RedditPost{Stickied: true}
+	nsfw := RedditPost{Over18: true}
+	spoiler := RedditPost{Spoiler: true}
+	crosspost := RedditPost{CrosspostParent: "t3_abc"}
+	plain := RedditPost{}
+
+	tests := []struct {
+		name     string
+		content  *config.Content
+		post     RedditPost
+		expected bool
+	}{
+		{"stickied without content options", nil, stickied, false},
+		{"plain without content options", nil, plain, true},
+		{"NSFW without content options", nil, nsfw, true},
+		{"stickied included", &config.Content{Stickied: config.ContentInclude}, stickied, true},
+		{"NSFW excluded", &config.Content{NSFW: config.ContentExclude}, nsfw, false},
+		{"plain with NSFW excluded", &config.Content{NSFW: config.ContentExclude}, plain, true},
+		{"NSFW only", &config.Content{NSFW: config.ContentOnly}, nsfw, true},
+		{"plain with NSFW only", &config.Content{NSFW: config.ContentOnly}, plain, false},
+		{"spoiler excluded", &config.Content{Spoiler: config.ContentExclude}, spoiler, false},
+		{"spoiler only", &config.Content{Spoiler: config.ContentOnly}, spoiler, true},
+		{"crosspost excluded", &config.Content{Crossposts: config.ContentExclude}, crosspost, false},
+		{"plain with crossposts only", &config.Content{Crossposts: config.ContentOnly}, plain, false},
+		{"NSFW crosspost with crossposts only and NSFW excluded", &config.Content{NSFW: config.ContentExclude, Crossposts: config.ContentOnly}, RedditPost{Over18: true, CrosspostParent: "t3_abc"}, false},
+	}
+	for _, test := range tests {
+		if included := includePost(test.post, test.content); included != test.expected {
+			t.Errorf("%s: includePost() = %t, expected %t", test.name, included, test.expected)
+		}
+	}
+}
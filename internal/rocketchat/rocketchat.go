@@ -0,0 +1,56 @@
+package rocketchat
+
+import (
+	"log"
+	"xenigo/internal/output"
+	"xenigo/internal/slack"
+)
+
+// Rocket.Chat accepts Slack style attachments, with an alias and avatar instead of a username and icon
+type RocketChatMessage struct {
+	Text        string                 `json:"text,omitempty"`
+	Alias       string                 `json:"alias,omitempty"`
+	Avatar      string                 `json:"avatar,omitempty"`
+	Emoji       string                 `json:"emoji,omitempty"`
+	Channel     string                 `json:"channel,omitempty"`
+	Attachments []RocketChatAttachment `json:"attachments"`
+}
+
+type RocketChatAttachment struct {
+	slack.SlackAttachment
+	Collapsed bool `json:"collapsed,omitempty"`
+}
+
+type RocketChatSender struct {
+	WebhookURL string
+	Alias      string // Overrides the name shown for the webhook
+	Avatar     string // Overrides the avatar of the webhook with an image URL
+	Emoji      string // Overrides the avatar of the webhook with an emoji, e.g. :ghost:
+	Channel    string // Overrides the channel of the webhook, e.g. #general or @username
+}
+
+func (r *RocketChatSender) SendMessage(embed output.MessageEmbed) error {
+	log.Printf("Sending message to Rocket.Chat: %s", embed.Title)
+	return slack.PostWebhook(r.WebhookURL, r.message(embed))
+}
+
+// SendMessages sends the embeds as a single message with one attachment per post
+func (r *RocketChatSender) SendMessages(embeds []output.MessageEmbed) error {
+	log.Printf("Sending %d posts to Rocket.Chat", len(embeds))
+	return slack.PostWebhook(r.WebhookURL, r.message(embeds...))
+}
+
+func (r *RocketChatSender) message(embeds ...output.MessageEmbed) RocketChatMessage {
+	message := RocketChatMessage{Alias: r.Alias, Avatar: r.Avatar, Emoji: r.Emoji, Channel: r.Channel}
+	for _, embed := range embeds {
+		attachment := RocketChatAttachment{SlackAttachment: slack.NewAttachment(embed)}
+		// Collapsed attachments keep their content hidden until they are expanded
+		if embed.Spoiler {
+			attachment.Text = embed.Description
+			attachment.ImageURL = embed.ImageURL
+			attachment.Collapsed = true
+		}
+		message.Attachments = append(message.Attachments, attachment)
+	}
+	return message
+}
@@ -0,0 +1,51 @@
+package rocketchat
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"xenigo/internal/output"
+)
+
+func TestSendMessage(t *testing.T) {
+	var message map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&message)
+		w.Write([]byte(`{"success":true}`))
+	}))
+	defer server.Close()
+
+	sender := &RocketChatSender{WebhookURL: server.URL, Alias: "xenigo", Channel: "#reddit"}
+	err := sender.SendMessage(output.MessageEmbed{
+		Title:       "A post",
+		Description: "NSFW body",
+		URL:         "https://example.org/post",
+		Author:      "someone",
+		Spoiler:     true,
+		Fields:      []output.EmbedField{{Name: "Subreddit", Value: "selfhosted"}},
+	})
+	if err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+
+	if message["alias"] != "xenigo" || message["channel"] != "#reddit" {
+		t.Errorf("unexpected overrides in %v", message)
+	}
+	attachment := message["attachments"].([]interface{})[0].(map[string]interface{})
+	expected := map[string]interface{}{
+		"title":       "[NSFW] A post",
+		"title_link":  "https://example.org/post",
+		"author_name": "u/someone",
+		"text":        "NSFW body",
+		"collapsed":   true,
+	}
+	for key, value := range expected {
+		if attachment[key] != value {
+			t.Errorf("attachment %s = %v, expected %v", key, attachment[key], value)
+		}
+	}
+	if fields := attachment["fields"].([]interface{}); len(fields) != 1 || fields[0].(map[string]interface{})["short"] != true {
+		t.Errorf("unexpected fields %v", fields)
+	}
+}
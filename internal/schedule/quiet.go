@@ -0,0 +1,65 @@
+package schedule
+
+import (
+	"strings"
+	"time"
+	"xenigo/internal/config"
+)
+
+// IsQuiet reports whether the output schedule is within any of its quiet hours at the given time.
+func IsQuiet(schedule *config.OutputSchedule, now time.Time) bool {
+	if schedule == nil {
+		return false
+	}
+	// Validated when loading the config
+	location, err := time.LoadLocation(schedule.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+	now = now.In(location)
+	minute := now.Hour()*60 + now.Minute()
+
+	for _, quiet := range schedule.QuietHours {
+		start, startErr := parseClock(quiet.Start)
+		end, endErr := parseClock(quiet.End)
+		if startErr != nil || endErr != nil {
+			continue
+		}
+
+		if start <= end {
+			if onDay(quiet.Days, now.Weekday()) && minute >= start && minute < end {
+				return true
+			}
+			continue
+		}
+		// Wraps past midnight, the range belongs to the day it starts on
+		if onDay(quiet.Days, now.Weekday()) && minute >= start {
+			return true
+		}
+		if onDay(quiet.Days, now.AddDate(0, 0, -1).Weekday()) && minute < end {
+			return true
+		}
+	}
+	return false
+}
+
+// parseClock returns the minutes since midnight of a HH:MM time
+func parseClock(clock string) (int, error) {
+	parsed, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, err
+	}
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
+
+func onDay(days []string, weekday time.Weekday) bool {
+	if len(days) == 0 {
+		return true
+	}
+	for _, day := range days {
+		if config.Weekdays[strings.ToLower(day)] == weekday {
+			return true
+		}
+	}
+	return false
+}
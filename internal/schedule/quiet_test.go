@@ -0,0 +1,39 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+	"xenigo/internal/config"
+)
+
+func TestIsQuiet(t *testing.T) {
+	schedule := &config.OutputSchedule{
+		Timezone: "Europe/Amsterdam",
+		QuietHours: []config.TimeRange{
+			{Days: []string{"mon", "tue", "wed", "thu", "fri"}, Start: "22:00", End: "07:00"},
+			{Days: []string{"sat", "sun"}, Start: "00:00", End: "10:00"},
+		},
+	}
+	location, _ := time.LoadLocation("Europe/Amsterdam")
+
+	tests := []struct {
+		name     string
+		at       time.Time
+		expected bool
+	}{
+		{"Weekday evening", time.Date(2024, 6, 3, 23, 0, 0, 0, location), true},
+		{"Weekday morning after midnight", time.Date(2024, 6, 4, 6, 59, 0, 0, location), true},
+		{"Weekday daytime", time.Date(2024, 6, 4, 7, 0, 0, 0, location), false},
+		{"Saturday morning after friday night", time.Date(2024, 6, 8, 3, 0, 0, 0, location), true},
+		{"Monday morning after sunday", time.Date(2024, 6, 3, 3, 0, 0, 0, location), false},
+		{"Other timezone", time.Date(2024, 6, 3, 21, 30, 0, 0, time.UTC), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if quiet := IsQuiet(schedule, tt.at); quiet != tt.expected {
+				t.Errorf("IsQuiet() = %v, expected %v", quiet, tt.expected)
+			}
+		})
+	}
+}
@@ -6,22 +6,42 @@ import (
     "fmt"
     "log"
     "net/http"
+    "strings"
     "xenigo/internal/output"
 )
 
+const maxBlocksPerMessage = 50
+
 type SlackMessage struct {
-    Attachments []SlackAttachment `json:"attachments"`
+    Text        string            `json:"text,omitempty"`
+    Blocks      []SlackBlock      `json:"blocks,omitempty"`
+    Attachments []SlackAttachment `json:"attachments,omitempty"`
+}
+
+type SlackBlock struct {
+    Type string     `json:"type"`
+    Text *SlackText `json:"text,omitempty"`
+}
+
+type SlackText struct {
+    Type string `json:"type"`
+    Text string `json:"text"`
 }
 
 type SlackAttachment struct {
-    Title  string       `json:"title"`
-    Text   string       `json:"text"`
-    Fields []SlackField `json:"fields,omitempty"`
+    Fallback   string       `json:"fallback,omitempty"`
+    AuthorName string       `json:"author_name,omitempty"`
+    Title      string       `json:"title"`
+    TitleLink  string       `json:"title_link,omitempty"`
+    Text       string       `json:"text"`
+    Fields     []SlackField `json:"fields,omitempty"`
+    ImageURL   string       `json:"image_url,omitempty"`
 }
 
 type SlackField struct {
     Title string `json:"title"`
     Value string `json:"value"`
+    Short bool   `json:"short,omitempty"`
 }
 
 type SlackSender struct {
@@ -37,13 +57,53 @@ func (s *SlackSender) SendMessage(embed output.MessageEmbed) error {
         Fields: convertFields(embed.Fields),
     }
 
-    message := SlackMessage{Attachments: []SlackAttachment{slackAttachment}}
+    message := SlackMessage{
+        Text:        buildMentions(embed.Mentions),
+        Attachments: []SlackAttachment{slackAttachment},
+    }
+    return PostWebhook(s.WebhookURL, message)
+}
+
+// SendMessages sends the embeds as a block list, split over several messages to stay within Slack's block limit.
+func (s *SlackSender) SendMessages(embeds []output.MessageEmbed) error {
+    log.Printf("Sending %d posts to Slack", len(embeds))
+
+    // One block is reserved for the header
+    perMessage := maxBlocksPerMessage - 1
+    for start := 0; start < len(embeds); start += perMessage {
+        end := start + perMessage
+        if end > len(embeds) {
+            end = len(embeds)
+        }
+
+        var mentions output.Mentions
+        header := fmt.Sprintf("%d new posts", end-start)
+        blocks := []SlackBlock{{Type: "header", Text: &SlackText{Type: "plain_text", Text: header}}}
+        for _, embed := range embeds[start:end] {
+            blocks = append(blocks, SlackBlock{Type: "section", Text: &SlackText{Type: "mrkdwn", Text: formatBlock(embed)}})
+            mentions.Add(output.Mentions{SlackGroups: embed.Mentions.SlackGroups, SlackUsers: embed.Mentions.SlackUsers})
+        }
+
+        // The text is used for notifications, so it includes the mentions
+        text := header
+        if pings := buildMentions(mentions); pings != "" {
+            text = pings + " " + header
+        }
+        if err := PostWebhook(s.WebhookURL, SlackMessage{Text: text, Blocks: blocks}); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// PostWebhook posts the message to a Slack compatible incoming webhook
+func PostWebhook(webhookURL string, message interface{}) error {
     messageBody, err := json.Marshal(message)
     if err != nil {
         return fmt.Errorf("failed to marshal message body: %w", err)
     }
 
-    resp, err := http.Post(s.WebhookURL, "application/json", bytes.NewBuffer(messageBody))
+    resp, err := http.Post(webhookURL, "application/json", bytes.NewBuffer(messageBody))
     if err != nil {
         return fmt.Errorf("failed to send message: %w", err)
     }
@@ -56,6 +116,68 @@ func (s *SlackSender) SendMessage(embed output.MessageEmbed) error {
     return nil
 }
 
+// formatBlock renders the embed as a linked title followed by its fields on one line
+func formatBlock(embed output.MessageEmbed) string {
+    title := embed.Title
+    if embed.URL != "" {
+        title = fmt.Sprintf("<%s|%s>", embed.URL, escapeText(embed.Title))
+    }
+
+    details := []string{}
+    if embed.Author != "" {
+        details = append(details, "u/"+embed.Author)
+    }
+    for _, field := range embed.Fields {
+        details = append(details, fmt.Sprintf("%s: %s", field.Name, field.Value))
+    }
+    return fmt.Sprintf("*%s*\n%s", title, strings.Join(details, " • "))
+}
+
+// escapeText escapes the control characters of Slack's mrkdwn
+func escapeText(text string) string {
+    return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+// buildMentions returns the text pinging the user groups and users
+func buildMentions(mentions output.Mentions) string {
+    var pings []string
+    for _, group := range mentions.SlackGroups {
+        pings = append(pings, fmt.Sprintf("<!subteam^%s>", group))
+    }
+    for _, user := range mentions.SlackUsers {
+        pings = append(pings, fmt.Sprintf("<@%s>", user))
+    }
+    return strings.Join(pings, " ")
+}
+
+// NewAttachment renders the embed as a linked attachment for Slack compatible chats, which render
+// the text as markdown. Spoilers leave out the text and the image.
+func NewAttachment(embed output.MessageEmbed) SlackAttachment {
+    attachment := SlackAttachment{
+        Fallback:  embed.Title,
+        Title:     embed.Title,
+        TitleLink: embed.URL,
+        Text:      embed.Description,
+        ImageURL:  embed.ImageURL,
+    }
+    if embed.Author != "" {
+        attachment.AuthorName = "u/" + embed.Author
+    }
+    if embed.Spoiler {
+        attachment.Title = "[NSFW] " + embed.Title
+        attachment.Text = ""
+        attachment.ImageURL = ""
+    }
+    for _, field := range embed.Fields {
+        attachment.Fields = append(attachment.Fields, SlackField{
+            Title: field.Name,
+            Value: field.Value,
+            Short: len(field.Value) <= 40, // Short fields are shown side by side
+        })
+    }
+    return attachment
+}
+
 func convertFields(fields []output.EmbedField) []SlackField {
     var slackFields []SlackField
     for _, field := range fields {
@@ -0,0 +1,54 @@
+package slack
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"xenigo/internal/output"
+)
+
+func TestSendMessage(t *testing.T) {
+	var message map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&message)
+	}))
+	defer server.Close()
+
+	sender := &SlackSender{WebhookURL: server.URL}
+	err := sender.SendMessage(output.MessageEmbed{
+		Title:       "A post",
+		Description: "Body",
+		URL:         "https://example.org/post",
+		Author:      "someone",
+		Fields:      []output.EmbedField{{Name: "Subreddit", Value: "selfhosted"}},
+		Mentions:    output.Mentions{SlackGroups: []string{"S1"}},
+	})
+	if err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+
+	if message["text"] != "<!subteam^S1>" {
+		t.Errorf("text = %v, expected the mention", message["text"])
+	}
+	// The attachment only holds the title, text and fields, the fields added for other outputs are left out
+	attachment := message["attachments"].([]interface{})[0].(map[string]interface{})
+	expected := map[string]interface{}{
+		"title":  "A post",
+		"text":   "Body",
+		"fields": []interface{}{map[string]interface{}{"title": "Subreddit", "value": "selfhosted"}},
+	}
+	if len(attachment) != len(expected) {
+		t.Errorf("unexpected attachment %v", attachment)
+	}
+	for key, value := range expected {
+		if encoded, _ := json.Marshal(attachment[key]); string(encoded) != mustMarshal(value) {
+			t.Errorf("attachment %s = %s, expected %s", key, encoded, mustMarshal(value))
+		}
+	}
+}
+
+func mustMarshal(value interface{}) string {
+	encoded, _ := json.Marshal(value)
+	return string(encoded)
+}
@@ -0,0 +1,185 @@
+package teams
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"unicode/utf8"
+	"xenigo/internal/output"
+)
+
+// Teams rejects messages larger than about 28 KB, including the card envelope
+const maxPayloadSize = 27 * 1024
+
+type TeamsMessage struct {
+	Type        string            `json:"type"`
+	Attachments []TeamsAttachment `json:"attachments"`
+}
+
+type TeamsAttachment struct {
+	ContentType string       `json:"contentType"`
+	Content     AdaptiveCard `json:"content"`
+}
+
+type AdaptiveCard struct {
+	Schema  string        `json:"$schema,omitempty"`
+	Type    string        `json:"type"`
+	Version string        `json:"version,omitempty"`
+	Body    []CardElement `json:"body"`
+	Actions []CardAction  `json:"actions,omitempty"`
+	MSTeams *CardMSTeams  `json:"msteams,omitempty"`
+}
+
+type CardMSTeams struct {
+	Width string `json:"width"`
+}
+
+// CardElement is a TextBlock, FactSet or Image, only the fields of its type are set
+type CardElement struct {
+	Type    string     `json:"type"`
+	Text    string     `json:"text,omitempty"`
+	Size    string     `json:"size,omitempty"`
+	Weight  string     `json:"weight,omitempty"`
+	Wrap    bool       `json:"wrap,omitempty"`
+	Facts   []CardFact `json:"facts,omitempty"`
+	URL     string     `json:"url,omitempty"`
+	AltText string     `json:"altText,omitempty"`
+}
+
+type CardFact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+// CardAction is an Action.OpenUrl or an Action.ShowCard revealing a nested card
+type CardAction struct {
+	Type  string        `json:"type"`
+	Title string        `json:"title"`
+	URL   string        `json:"url,omitempty"`
+	Card  *AdaptiveCard `json:"card,omitempty"`
+}
+
+type TeamsSender struct {
+	WebhookURL string
+}
+
+func (t *TeamsSender) SendMessage(embed output.MessageEmbed) error {
+	log.Printf("Sending message to Teams: %s", embed.Title)
+
+	body, err := buildPayload(embed)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.Post(t.WebhookURL, "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		return fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Incoming webhooks answer with 200, Workflows accept the message with 202
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("received non-200 response code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// buildPayload renders the embed as an Adaptive Card message, shortening the description
+// until the message fits within the payload size limit of Teams
+func buildPayload(embed output.MessageEmbed) ([]byte, error) {
+	description := embed.Description
+	embed.Description = ""
+	envelope, err := marshalMessage(embed)
+	if err != nil || description == "" {
+		return envelope, err
+	}
+
+	for length := utf8.RuneCountInString(description); length > 0; {
+		embed.Description = description
+		body, err := marshalMessage(embed)
+		if err != nil || len(body) <= maxPayloadSize {
+			return body, err
+		}
+
+		// Escaping makes some characters take up several bytes, so scale the length by how much
+		// room the description takes up and shave off one more character to always make progress
+		length = length*(maxPayloadSize-len(envelope))/(len(body)-len(envelope)) - 1
+		if length > 0 {
+			description = string([]rune(description)[:length]) + "…"
+		}
+	}
+	return envelope, nil
+}
+
+func marshalMessage(embed output.MessageEmbed) ([]byte, error) {
+	body, err := json.Marshal(TeamsMessage{
+		Type: "message",
+		Attachments: []TeamsAttachment{{
+			ContentType: "application/vnd.microsoft.card.adaptive",
+			Content:     buildCard(embed),
+		}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal webhook body: %w", err)
+	}
+	return body, nil
+}
+
+func buildCard(embed output.MessageEmbed) AdaptiveCard {
+	card := AdaptiveCard{
+		Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+		Type:    "AdaptiveCard",
+		Version: "1.4",
+		Body: []CardElement{
+			{Type: "TextBlock", Text: embed.Title, Size: "Medium", Weight: "Bolder", Wrap: true},
+		},
+		MSTeams: &CardMSTeams{Width: "Full"},
+	}
+
+	var facts []CardFact
+	if embed.Author != "" {
+		facts = append(facts, CardFact{Title: "Author", Value: "u/" + embed.Author})
+	}
+	var discussionURL string
+	for _, field := range embed.Fields {
+		if field.Name == "Discussion URL" {
+			discussionURL = field.Value
+			continue
+		}
+		facts = append(facts, CardFact{Title: field.Name, Value: field.Value})
+	}
+	if len(facts) > 0 {
+		card.Body = append(card.Body, CardElement{Type: "FactSet", Facts: facts})
+	}
+
+	// Adaptive Cards have no spoilers, so NSFW content is only shown on request
+	if embed.Spoiler {
+		hidden := &AdaptiveCard{Type: "AdaptiveCard"}
+		if embed.Description != "" {
+			hidden.Body = append(hidden.Body, CardElement{Type: "TextBlock", Text: embed.Description, Wrap: true})
+		}
+		if embed.ImageURL != "" {
+			hidden.Body = append(hidden.Body, CardElement{Type: "Image", URL: embed.ImageURL, AltText: embed.Title})
+		}
+		if len(hidden.Body) > 0 {
+			card.Actions = append(card.Actions, CardAction{Type: "Action.ShowCard", Title: "Show NSFW content", Card: hidden})
+		}
+	} else {
+		if embed.Description != "" {
+			card.Body = append(card.Body, CardElement{Type: "TextBlock", Text: embed.Description, Wrap: true})
+		}
+		if embed.ImageURL != "" {
+			card.Body = append(card.Body, CardElement{Type: "Image", URL: embed.ImageURL, AltText: embed.Title})
+		}
+	}
+
+	if embed.URL != "" {
+		card.Actions = append(card.Actions, CardAction{Type: "Action.OpenUrl", Title: "Open post", URL: embed.URL})
+	}
+	if discussionURL != "" && discussionURL != embed.URL {
+		card.Actions = append(card.Actions, CardAction{Type: "Action.OpenUrl", Title: "Open discussion", URL: discussionURL})
+	}
+	return card
+}
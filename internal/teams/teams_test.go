@@ -0,0 +1,38 @@
+package teams
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"xenigo/internal/output"
+)
+
+func TestBuildPayloadFitsSizeLimit(t *testing.T) {
+	embed := output.MessageEmbed{
+		Title:       "A very long post",
+		Description: strings.Repeat(`"quoted" <text> `, 5000),
+		URL:         "https://example.org/post",
+		Author:      "someone",
+		Fields:      []output.EmbedField{{Name: "Subreddit", Value: "golang"}, {Name: "Discussion URL", Value: "https://reddit.com/r/golang/comments/abc"}},
+	}
+
+	body, err := buildPayload(embed)
+	if err != nil {
+		t.Fatalf("buildPayload() error = %v", err)
+	}
+	if len(body) > maxPayloadSize {
+		t.Errorf("buildPayload() returned %d bytes, limit is %d", len(body), maxPayloadSize)
+	}
+
+	var message TeamsMessage
+	if err := json.Unmarshal(body, &message); err != nil {
+		t.Fatalf("payload is not valid JSON: %v", err)
+	}
+	card := message.Attachments[0].Content
+	if len(card.Body) != 3 || card.Body[1].Type != "FactSet" || !strings.HasSuffix(card.Body[2].Text, "…") {
+		t.Errorf("expected title, facts and a truncated description, got %+v", card.Body)
+	}
+	if len(card.Actions) != 2 || card.Actions[0].URL != embed.URL || card.Actions[1].Title != "Open discussion" {
+		t.Errorf("unexpected actions %+v", card.Actions)
+	}
+}
@@ -0,0 +1,197 @@
+package telegram
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+	"xenigo/internal/output"
+)
+
+// Telegram limits the length of messages and of photo captions
+const (
+	maxMessageLength = 4096
+	maxCaptionLength = 1024
+)
+
+type TelegramSender struct {
+	APIURL     string
+	BotToken   string
+	ChatID     string
+	ParseMode  string
+	SendPhotos bool
+}
+
+type sendMessageRequest struct {
+	ChatID    string `json:"chat_id"`
+	Text      string `json:"text"`
+	ParseMode string `json:"parse_mode"`
+}
+
+type sendPhotoRequest struct {
+	ChatID     string `json:"chat_id"`
+	Photo      string `json:"photo"`
+	Caption    string `json:"caption"`
+	ParseMode  string `json:"parse_mode"`
+	HasSpoiler bool   `json:"has_spoiler,omitempty"`
+}
+
+type apiResponse struct {
+	OK          bool   `json:"ok"`
+	Description string `json:"description"`
+}
+
+func (t *TelegramSender) SendMessage(embed output.MessageEmbed) error {
+	log.Printf("Sending message to Telegram: %s", embed.Title)
+
+	if t.SendPhotos && embed.ImageURL != "" {
+		return t.call("sendPhoto", sendPhotoRequest{
+			ChatID:     t.ChatID,
+			Photo:      embed.ImageURL,
+			Caption:    t.render(embed, maxCaptionLength),
+			ParseMode:  t.ParseMode,
+			HasSpoiler: embed.Spoiler,
+		})
+	}
+	return t.call("sendMessage", sendMessageRequest{
+		ChatID:    t.ChatID,
+		Text:      t.render(embed, maxMessageLength),
+		ParseMode: t.ParseMode,
+	})
+}
+
+func (t *TelegramSender) call(method string, request interface{}) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	resp, err := http.Post(fmt.Sprintf("%s/bot%s/%s", t.APIURL, t.BotToken, method), "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		// The error contains the URL, which contains the bot token
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			urlErr.URL = fmt.Sprintf("%s/bot********/%s", t.APIURL, method)
+		}
+		return fmt.Errorf("failed to send message to %s: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	var result apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode response with code %d: %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK || !result.OK {
+		return fmt.Errorf("received non-200 response code: %d, %s", resp.StatusCode, result.Description)
+	}
+	return nil
+}
+
+// render formats the embed in the parse mode of the sender, shortening the description to stay within the limit
+func (t *TelegramSender) render(embed output.MessageEmbed, limit int) string {
+	format := formatHTML
+	if t.ParseMode == "MarkdownV2" {
+		format = formatMarkdownV2
+	}
+
+	message := format(embed, "")
+	if embed.Description == "" {
+		return message
+	}
+
+	// The escaped description may be longer than the original, so shrink it until the whole message fits
+	description := embed.Description
+	budget := limit - utf8.RuneCountInString(message)
+	for budget > 0 {
+		description = output.Truncate(description, budget)
+		withDescription := format(embed, description)
+		if utf8.RuneCountInString(withDescription) <= limit {
+			return withDescription
+		}
+		// Scale the budget by how much escaping grew the description, then shave off one more character
+		// so the loop always makes progress
+		length := utf8.RuneCountInString(withDescription)
+		budget = budget*(limit-utf8.RuneCountInString(message))/(length-utf8.RuneCountInString(message)) - 1
+	}
+	return message
+}
+
+func formatHTML(embed output.MessageEmbed, description string) string {
+	var b strings.Builder
+	title := escapeHTML(embed.Title)
+	if embed.URL != "" {
+		title = fmt.Sprintf(`<a href="%s">%s</a>`, escapeHTML(embed.URL), title)
+	}
+	fmt.Fprintf(&b, "<b>%s</b>", title)
+	if embed.Author != "" {
+		fmt.Fprintf(&b, "\n<i>by u/%s</i>", escapeHTML(embed.Author))
+	}
+	if description != "" {
+		description = escapeHTML(description)
+		if embed.Spoiler {
+			description = "<tg-spoiler>" + description + "</tg-spoiler>"
+		}
+		fmt.Fprintf(&b, "\n\n%s", description)
+	}
+	if len(embed.Fields) > 0 {
+		b.WriteString("\n")
+	}
+	for _, field := range embed.Fields {
+		fmt.Fprintf(&b, "\n<b>%s:</b> %s", escapeHTML(field.Name), escapeHTML(field.Value))
+	}
+	return b.String()
+}
+
+func formatMarkdownV2(embed output.MessageEmbed, description string) string {
+	var b strings.Builder
+	title := escapeMarkdownV2(embed.Title)
+	if embed.URL != "" {
+		title = fmt.Sprintf("[%s](%s)", title, escapeMarkdownV2URL(embed.URL))
+	}
+	fmt.Fprintf(&b, "*%s*", title)
+	if embed.Author != "" {
+		fmt.Fprintf(&b, "\n_by u/%s_", escapeMarkdownV2(embed.Author))
+	}
+	if description != "" {
+		description = escapeMarkdownV2(description)
+		if embed.Spoiler {
+			description = "||" + description + "||"
+		}
+		fmt.Fprintf(&b, "\n\n%s", description)
+	}
+	if len(embed.Fields) > 0 {
+		b.WriteString("\n")
+	}
+	for _, field := range embed.Fields {
+		fmt.Fprintf(&b, "\n*%s:* %s", escapeMarkdownV2(field.Name), escapeMarkdownV2(field.Value))
+	}
+	return b.String()
+}
+
+var htmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+
+func escapeHTML(text string) string {
+	return htmlEscaper.Replace(text)
+}
+
+// Every character reserved by MarkdownV2 has to be escaped outside of entities
+var markdownV2Escaper = strings.NewReplacer(
+	`\`, `\\`, "_", `\_`, "*", `\*`, "[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`, "~", `\~`, "`", "\\`",
+	">", `\>`, "#", `\#`, "+", `\+`, "-", `\-`, "=", `\=`, "|", `\|`, "{", `\{`, "}", `\}`, ".", `\.`, "!", `\!`,
+)
+
+func escapeMarkdownV2(text string) string {
+	return markdownV2Escaper.Replace(text)
+}
+
+// Inside the URL part of a link only ")" and "\" have to be escaped
+var markdownV2URLEscaper = strings.NewReplacer(`\`, `\\`, ")", `\)`)
+
+func escapeMarkdownV2URL(url string) string {
+	return markdownV2URLEscaper.Replace(url)
+}
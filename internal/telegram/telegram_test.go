@@ -0,0 +1,128 @@
+package telegram
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"xenigo/internal/output"
+)
+
+func TestSendMessage(t *testing.T) {
+	var path string
+	var request map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		json.NewDecoder(r.Body).Decode(&request)
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	embed := output.MessageEmbed{
+		Title:       "[USA-CA] [H] 3080 <FE> [W] PayPal",
+		Description: "Price is $450 & shipping is free",
+		URL:         "https://www.reddit.com/r/hardwareswap/comments/abc/title/",
+		Author:      "seller_1",
+		Fields:      []output.EmbedField{{Name: "Subreddit", Value: "hardwareswap"}},
+	}
+
+	tests := []struct {
+		name      string
+		parseMode string
+		embed     output.MessageEmbed
+		method    string
+		expected  string
+	}{
+		{
+			name:      "HTML",
+			parseMode: "HTML",
+			embed:     embed,
+			method:    "sendMessage",
+			expected:  "<b><a href=\"https://www.reddit.com/r/hardwareswap/comments/abc/title/\">[USA-CA] [H] 3080 &lt;FE&gt; [W] PayPal</a></b>\n<i>by u/seller_1</i>\n\nPrice is $450 &amp; shipping is free\n\n<b>Subreddit:</b> hardwareswap",
+		},
+		{
+			name:      "MarkdownV2",
+			parseMode: "MarkdownV2",
+			embed:     embed,
+			method:    "sendMessage",
+			expected:  "*[\\[USA\\-CA\\] \\[H\\] 3080 <FE\\> \\[W\\] PayPal](https://www.reddit.com/r/hardwareswap/comments/abc/title/)*\n_by u/seller\\_1_\n\nPrice is $450 & shipping is free\n\n*Subreddit:* hardwareswap",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender := &TelegramSender{APIURL: server.URL, BotToken: "123:abc", ChatID: "-100", ParseMode: tt.parseMode}
+			if err := sender.SendMessage(tt.embed); err != nil {
+				t.Fatalf("SendMessage() error = %v", err)
+			}
+			if path != "/bot123:abc/"+tt.method {
+				t.Errorf("SendMessage() called %s, expected %s", path, tt.method)
+			}
+			if request["text"] != tt.expected {
+				t.Errorf("SendMessage() text = %q, expected %q", request["text"], tt.expected)
+			}
+			if request["parse_mode"] != tt.parseMode {
+				t.Errorf("SendMessage() parse_mode = %q, expected %q", request["parse_mode"], tt.parseMode)
+			}
+		})
+	}
+}
+
+func TestSendPhotoTruncatesCaption(t *testing.T) {
+	var request map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/sendPhoto") {
+			t.Errorf("expected sendPhoto, got %s", r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	sender := &TelegramSender{APIURL: server.URL, BotToken: "123:abc", ChatID: "-100", ParseMode: "HTML", SendPhotos: true}
+	err := sender.SendMessage(output.MessageEmbed{
+		Title:       "Cat",
+		Description: strings.Repeat("<&>", 1000),
+		ImageURL:    "https://i.redd.it/cat.jpg",
+	})
+	if err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+	if request["photo"] != "https://i.redd.it/cat.jpg" {
+		t.Errorf("SendMessage() photo = %v", request["photo"])
+	}
+	if caption := request["caption"].(string); len([]rune(caption)) > maxCaptionLength || !strings.HasSuffix(caption, "…") {
+		t.Errorf("SendMessage() caption of %d characters not truncated to %d", len([]rune(caption)), maxCaptionLength)
+	}
+}
+
+func TestSendMessageError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"ok":false,"description":"Bad Request: chat not found"}`))
+	}))
+	defer server.Close()
+
+	sender := &TelegramSender{APIURL: server.URL, BotToken: "123:abc", ChatID: "-100", ParseMode: "HTML"}
+	if err := sender.SendMessage(output.MessageEmbed{Title: "Cat"}); err == nil || !strings.Contains(err.Error(), "chat not found") {
+		t.Errorf("SendMessage() error = %v, expected chat not found", err)
+	}
+}
+
+func TestSendMessageTransportError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	sender := &TelegramSender{APIURL: server.URL, BotToken: "123:abc", ChatID: "-100", ParseMode: "HTML"}
+	err := sender.SendMessage(output.MessageEmbed{Title: "Cat"})
+	var netErr net.Error
+	if !errors.As(err, &netErr) {
+		t.Errorf("SendMessage() error = %v, expected the cause to be kept", err)
+	}
+	if err != nil && strings.Contains(err.Error(), "123:abc") {
+		t.Errorf("SendMessage() error = %v contains the bot token", err)
+	}
+}
@@ -0,0 +1,161 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"text/template"
+	"text/template/parse"
+	"time"
+	"xenigo/internal/output"
+)
+
+type WebhookSender struct {
+	URL            string
+	Method         string
+	Headers        map[string]string // Values may reference environment variables as ${NAME}
+	ExpectedStatus []int             // Any 2xx status when empty
+	Body           *template.Template
+}
+
+var client = &http.Client{Timeout: 10 * time.Second}
+
+// ParseTemplate parses a JSON body template. Besides the standard template functions,
+// json encodes a value, e.g. {"title": {{ json .Post.Title }}}. Without a template nil is returned
+// and the post data is sent as is.
+func ParseTemplate(text string) (*template.Template, error) {
+	if text == "" {
+		return nil, nil
+	}
+	return template.New("body").Funcs(template.FuncMap{
+		"json": func(value interface{}) (string, error) {
+			encoded, err := json.Marshal(value)
+			return string(encoded), err
+		},
+	}).Parse(text)
+}
+
+// UsesPost reports whether the template refers to .Post, which digests and posts held during
+// quiet hours don't carry. Those templates should use .Embed instead.
+func UsesPost(tmpl *template.Template) bool {
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil && usesPost(t.Tree.Root) {
+			return true
+		}
+	}
+	return false
+}
+
+func usesPost(node parse.Node) bool {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return false
+		}
+		for _, child := range n.Nodes {
+			if usesPost(child) {
+				return true
+			}
+		}
+	case *parse.ActionNode:
+		return usesPost(n.Pipe)
+	case *parse.IfNode:
+		return usesPost(n.Pipe) || usesPost(n.List) || usesPost(n.ElseList)
+	case *parse.RangeNode:
+		return usesPost(n.Pipe) || usesPost(n.List) || usesPost(n.ElseList)
+	case *parse.WithNode:
+		return usesPost(n.Pipe) || usesPost(n.List) || usesPost(n.ElseList)
+	case *parse.TemplateNode:
+		return usesPost(n.Pipe)
+	case *parse.PipeNode:
+		if n == nil {
+			return false
+		}
+		for _, cmd := range n.Cmds {
+			if usesPost(cmd) {
+				return true
+			}
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			if usesPost(arg) {
+				return true
+			}
+		}
+	case *parse.ChainNode:
+		return usesPost(n.Node)
+	case *parse.FieldNode:
+		return n.Ident[0] == "Post"
+	case *parse.VariableNode:
+		return len(n.Ident) > 1 && n.Ident[0] == "$" && n.Ident[1] == "Post"
+	}
+	return false
+}
+
+func (w *WebhookSender) SendMessage(embed output.MessageEmbed) error {
+	return w.SendPost(output.PostData{Embed: embed})
+}
+
+func (w *WebhookSender) SendPost(data output.PostData) error {
+	log.Printf("Sending message to webhook: %s", data.Embed.Title)
+
+	body, err := w.render(data)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(w.Method, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range w.Headers {
+		req.Header.Set(name, os.ExpandEnv(value))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if !w.expected(resp.StatusCode) {
+		return fmt.Errorf("received unexpected response code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// render executes the body template, or encodes the post data, including the embed, as is without one
+func (w *WebhookSender) render(data output.PostData) ([]byte, error) {
+	if w.Body == nil {
+		body, err := json.Marshal(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal webhook body: %w", err)
+		}
+		return body, nil
+	}
+
+	var buf bytes.Buffer
+	if err := w.Body.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render webhook body: %w", err)
+	}
+	if !json.Valid(buf.Bytes()) {
+		return nil, fmt.Errorf("webhook body template did not render valid JSON: %s", buf.String())
+	}
+	return buf.Bytes(), nil
+}
+
+func (w *WebhookSender) expected(status int) bool {
+	if len(w.ExpectedStatus) == 0 {
+		return status >= 200 && status < 300
+	}
+	for _, expected := range w.ExpectedStatus {
+		if status == expected {
+			return true
+		}
+	}
+	return false
+}
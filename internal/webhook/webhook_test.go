@@ -0,0 +1,122 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"xenigo/internal/output"
+)
+
+type post struct {
+	Title string
+	URL   string
+}
+
+func TestSendPost(t *testing.T) {
+	t.Setenv("WEBHOOK_TEST_TOKEN", "secret")
+
+	var body, auth, method string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body, auth, method = string(data), r.Header.Get("Authorization"), r.Method
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	tmpl, err := ParseTemplate(`{"title": {{ json .Post.Title }}, "price": {{ json .Fields.price }}, "target": {{ json .Target }}}`)
+	if err != nil {
+		t.Fatalf("ParseTemplate() error = %v", err)
+	}
+	sender := &WebhookSender{
+		URL:            server.URL,
+		Method:         http.MethodPut,
+		Headers:        map[string]string{"Authorization": "Bearer ${WEBHOOK_TEST_TOKEN}"},
+		ExpectedStatus: []int{http.StatusCreated},
+		Body:           tmpl,
+	}
+	err = sender.SendPost(output.PostData{
+		Target: "GPUs",
+		Post:   post{Title: `3080 "FE"`, URL: "https://example.org"},
+		Fields: map[string]string{"price": "450"},
+	})
+	if err != nil {
+		t.Fatalf("SendPost() error = %v", err)
+	}
+
+	if expected := `{"title": "3080 \"FE\"", "price": "450", "target": "GPUs"}`; body != expected {
+		t.Errorf("SendPost() body = %s, expected %s", body, expected)
+	}
+	if auth != "Bearer secret" {
+		t.Errorf("SendPost() Authorization = %q, expected the token from the environment", auth)
+	}
+	if method != http.MethodPut {
+		t.Errorf("SendPost() method = %s, expected PUT", method)
+	}
+
+	sender.ExpectedStatus = []int{http.StatusOK}
+	if err := sender.SendPost(output.PostData{Post: post{}}); err == nil {
+		t.Error("SendPost() expected an error for an unexpected status code")
+	}
+}
+
+func TestRenderInvalidJSON(t *testing.T) {
+	tmpl, err := ParseTemplate(`{"title": {{ .Post.Title }}}`)
+	if err != nil {
+		t.Fatalf("ParseTemplate() error = %v", err)
+	}
+	sender := &WebhookSender{Body: tmpl}
+	if _, err := sender.render(output.PostData{Post: post{Title: "unquoted"}}); err == nil {
+		t.Error("render() expected an error for a body that is not valid JSON")
+	}
+}
+
+func TestSendMessageDefaultBody(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+	}))
+	defer server.Close()
+
+	// Digests and held posts only reach the sender as an embed
+	sender := &WebhookSender{URL: server.URL, Method: http.MethodPost}
+	err := sender.SendMessage(output.MessageEmbed{Title: "A post", URL: "https://example.org/post", Author: "someone"})
+	if err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+
+	embed, ok := body["embed"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("body %v has no embed", body)
+	}
+	if embed["title"] != "A post" || embed["url"] != "https://example.org/post" || embed["author"] != "someone" {
+		t.Errorf("unexpected embed %v", embed)
+	}
+	if body["post"] != nil {
+		t.Errorf("post = %v, expected null", body["post"])
+	}
+}
+
+func TestUsesPost(t *testing.T) {
+	tests := []struct {
+		body     string
+		expected bool
+	}{
+		{`{"title": {{ json .Post.Title }}}`, true},
+		{`{"title": {{ json $.Post.Title }}}`, true},
+		{`{{ with .Post }}{"title": {{ json .Title }}}{{ end }}`, true},
+		{`{{ if .Fields.price }}{"title": {{ json .Post.Title }}}{{ else }}{}{{ end }}`, true},
+		{`{"title": {{ json .Embed.Title }}, "price": {{ json .Fields.price }}}`, false},
+		{`{"title": {{ .Embed.Title | json }}}`, false},
+	}
+	for _, test := range tests {
+		tmpl, err := ParseTemplate(test.body)
+		if err != nil {
+			t.Fatalf("ParseTemplate(%q) error = %v", test.body, err)
+		}
+		if uses := UsesPost(tmpl); uses != test.expected {
+			t.Errorf("UsesPost(%q) = %t, expected %t", test.body, uses, test.expected)
+		}
+	}
+}
@@ -48,12 +48,12 @@ func main() {
     // Log the startup information
     log.Println("Starting monitors with the following intervals:")
     for _, target := range config.Targets {
-        log.Printf("Monitor: %s, Interval: %d seconds\n", target.Monitor.Subreddit, target.Options.Interval)
+        log.Printf("Monitor: %s, Polling: %s\n", target.Monitor, NewPoller(target.Options))
     }
 
     // Start monitoring
     for _, target := range config.Targets {
-        go monitorSubreddit(target, accessToken, config.UserAgent, string(appConfig.Context), cache, sendInitial, config.DeveloperFlags, config.OAuth)
+        go monitorTarget(target, accessToken, config.UserAgent, string(appConfig.Context), cache, sendInitial, config.DeveloperFlags, config.OAuth)
     }
 
     // Periodically save the cache
@@ -4,27 +4,114 @@ import (
 	"log"
 	"time"
 	"xenigo/internal/config"
+	"xenigo/internal/filter"
 	"xenigo/internal/notifier"
 	"xenigo/internal/reddit"
 )
 
-func monitorSubreddit(target config.Target, accessToken, userAgent, context string, cache *Cache, sendInitial bool, devFlags *config.DeveloperFlags, oauthConfig *config.OAuthConfig) {
-    fetchAndProcess := func(sendToDiscord bool) {
-        log.Printf("Executing monitor check for subreddit: %s", target.Monitor.Subreddit)
-        redditResponse, err := reddit.FetchRedditData(target, accessToken, userAgent, context, oauthConfig)
+func monitorTarget(target config.Target, accessToken, userAgent, context string, cache *Cache, sendInitial bool, devFlags *config.DeveloperFlags, oauthConfig *config.OAuthConfig) {
+    var tracker *UpdateTracker
+    if target.TrackUpdates != nil {
+        tracker = NewUpdateTracker(time.Duration(target.TrackUpdates.Window) * time.Second)
+    }
+    var trends *TrendTracker
+    if target.Trending != nil {
+        trends = NewTrendTracker(*target.Trending)
+    }
+
+    // Posts drop out of the listing long before they trend, so the tracked posts
+    // that were not part of it are re-fetched to keep measuring them
+    checkTrending := func(observed map[string]bool, sendToDiscord bool) {
+        names := trends.Names(observed)
+        if len(names) == 0 {
+            return
+        }
+        posts, err := reddit.FetchPostsInfo(names, target.Options, accessToken, userAgent, context, oauthConfig)
         if err != nil {
-            log.Printf("Error fetching Reddit data for subreddit %s: %v", target.Monitor.Subreddit, err)
+            log.Printf("Error fetching trending posts for %s: %v", target.Monitor, err)
             return
         }
+        for _, post := range posts {
+            if reasons := trends.Observe(post); len(reasons) > 0 && sendToDiscord {
+                notifier.SendTrendingPost(post, reasons, target, devFlags)
+            }
+        }
+    }
+
+    fetchAndProcess := func(sendToDiscord bool) int {
+        log.Printf("Executing monitor check for: %s", target.Monitor)
+        redditResponse, err := reddit.FetchRedditData(target, accessToken, userAgent, context, oauthConfig)
+        if err != nil {
+            log.Printf("Error fetching Reddit data for %s: %v", target.Monitor, err)
+            return 0
+        }
+        newPosts := 0
+        var sentMessages []string
+        observed := make(map[string]bool)
         for _, child := range redditResponse.Data.Children {
             post := child.Data
+            // Posts that don't match the filters or keywords of the target are never sent
+            if len(target.Filters) > 0 || len(target.Keywords) > 0 {
+                fields := filter.Fields(post, target)
+                if !filter.Match(target.Filters, fields) || !filter.MatchKeywords(target.Keywords, fields) {
+                    continue
+                }
+            }
             // Check if the post has already been processed
-            if !cache.IsProcessed(post.Permalink) || config.GetFlag(devFlags.IgnoreCache) {
-                if sendToDiscord {
-                    notifier.ProcessAndSendPost(post, target, devFlags)
+            if !cache.IsProcessed(post.Key()) || config.GetFlag(devFlags.IgnoreCache) {
+                newPosts++
+                // Skip posts already sent within the dedupe scope, e.g. crossposts of the same link
+                duplicate := target.Dedupe != nil && cache.SeenOrRecord(dedupeKeys(post, target))
+                if duplicate {
+                    log.Printf("Skipping duplicate post for %s: %s", target.Name, post.Title)
+                }
+                if sendToDiscord && !duplicate {
+                    messageID, sent := notifier.ProcessAndSendPost(post, target, devFlags)
+                    // Inbox items that failed to send stay unread
+                    if sent {
+                        sentMessages = append(sentMessages, post.Name)
+                    }
+                    if tracker != nil {
+                        tracker.Track(post, messageID)
+                    }
                 }
                 // Mark the post as processed
-                cache.AddProcessedPermalink(post.Permalink)
+                cache.AddProcessedPermalink(post.Key())
+            }
+            // Trending is checked on every poll, not only when the post first appears
+            if trends != nil {
+                observed[post.Key()] = true
+                if reasons := trends.Observe(post); len(reasons) > 0 && sendToDiscord {
+                    notifier.SendTrendingPost(post, reasons, target, devFlags)
+                }
+            }
+        }
+        if trends != nil {
+            checkTrending(observed, sendToDiscord)
+            trends.Prune()
+        }
+        // Mark forwarded inbox items as read on Reddit
+        if target.Monitor.MarkRead && len(sentMessages) > 0 {
+            if err := reddit.MarkMessagesRead(sentMessages, accessToken, userAgent, oauthConfig); err != nil {
+                log.Printf("Error marking messages as read for %s: %v", target.Monitor, err)
+            }
+        }
+        return newPosts
+    }
+    checkForUpdates := func() {
+        names := tracker.Names()
+        if len(names) == 0 {
+            return
+        }
+        log.Printf("Checking %d tracked posts for updates for: %s", len(names), target.Monitor)
+        posts, err := reddit.FetchPostsInfo(names, target.Options, accessToken, userAgent, context, oauthConfig)
+        if err != nil {
+            log.Printf("Error fetching tracked posts for %s: %v", target.Monitor, err)
+            return
+        }
+        for _, post := range posts {
+            if changes, messageID := tracker.Update(post); len(changes) > 0 {
+                notifier.SendPostUpdate(post, changes, messageID, target, devFlags)
             }
         }
     }
@@ -32,10 +119,13 @@ func monitorSubreddit(target config.Target, accessToken, userAgent, context stri
     sendToDiscord := sendInitial
     // Run immediately on start
     fetchAndProcess(sendToDiscord)
-    // Set up the ticker for subsequent runs
-    ticker := time.NewTicker(time.Duration(target.Options.Interval) * time.Second)
-    defer ticker.Stop()
-    for range ticker.C {
-        fetchAndProcess(true)
+    // Subsequent runs follow the interval, cron schedule or adaptive polling of the target
+    poller := NewPoller(target.Options)
+    for {
+        time.Sleep(poller.Next(time.Now()))
+        poller.Observe(fetchAndProcess(true))
+        if tracker != nil {
+            checkForUpdates()
+        }
     }
 }
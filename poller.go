@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"time"
+	"xenigo/internal/config"
+	"xenigo/internal/cron"
+)
+
+// Poller decides when a target is polled next: at a fixed interval, following a cron schedule,
+// or adaptively based on whether recent polls found new posts.
+type Poller struct {
+	interval   time.Duration
+	expression string
+	schedule   *cron.Schedule
+	adaptive   *config.Adaptive
+}
+
+func NewPoller(options *config.Options) *Poller {
+	poller := &Poller{
+		interval: time.Duration(options.Interval) * time.Second,
+		adaptive: options.Adaptive,
+	}
+	if options.Schedule != "" {
+		// Validated when loading the config
+		poller.expression = options.Schedule
+		poller.schedule, _ = cron.Parse(options.Schedule)
+	}
+	if poller.adaptive != nil {
+		poller.interval = poller.clamp(poller.interval)
+	}
+	return poller
+}
+
+// Next returns how long to wait before the next poll. Intervals never go below the minimum interval.
+func (p *Poller) Next(now time.Time) time.Duration {
+	if p.schedule != nil {
+		return p.schedule.Next(now).Sub(now)
+	}
+	if minimum := config.MinimumInterval * time.Second; p.interval < minimum {
+		return minimum
+	}
+	return p.interval
+}
+
+// Observe adapts the interval to the number of new posts found by the last poll,
+// halving it while there's activity and backing off by half again while it's quiet.
+func (p *Poller) Observe(newPosts int) {
+	if p.adaptive == nil {
+		return
+	}
+	if newPosts > 0 {
+		p.interval = p.clamp(p.interval / 2)
+	} else {
+		p.interval = p.clamp(p.interval * 3 / 2)
+	}
+}
+
+func (p *Poller) clamp(interval time.Duration) time.Duration {
+	min := time.Duration(p.adaptive.MinInterval) * time.Second
+	max := time.Duration(p.adaptive.MaxInterval) * time.Second
+	if interval < min {
+		return min
+	}
+	if interval > max {
+		return max
+	}
+	return interval
+}
+
+// String describes the polling behaviour for the startup log
+func (p *Poller) String() string {
+	if p.schedule != nil {
+		return fmt.Sprintf("schedule %q", p.expression)
+	}
+	if p.adaptive != nil {
+		return fmt.Sprintf("adaptive between %d and %d seconds", p.adaptive.MinInterval, p.adaptive.MaxInterval)
+	}
+	return fmt.Sprintf("interval of %s", p.interval)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"testing"
+	"time"
+	"xenigo/internal/config"
+)
+
+func TestPollerNext(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 30, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		options  config.Options
+		expected time.Duration
+	}{
+		{"interval", config.Options{Interval: 60}, time.Minute},
+		{"zero interval is clamped", config.Options{}, config.MinimumInterval * time.Second},
+		{"short interval is clamped", config.Options{Interval: 1}, config.MinimumInterval * time.Second},
+		{"schedule", config.Options{Interval: 60, Schedule: "*/5 * * * *"}, 4*time.Minute + 30*time.Second},
+		{"adaptive starts at the interval", config.Options{Interval: 60, Adaptive: &config.Adaptive{MinInterval: 15, MaxInterval: 600}}, time.Minute},
+		{"adaptive starts within its bounds", config.Options{Interval: 900, Adaptive: &config.Adaptive{MinInterval: 15, MaxInterval: 600}}, 10 * time.Minute},
+	}
+	for _, test := range tests {
+		if next := NewPoller(&test.options).Next(now); next != test.expected {
+			t.Errorf("%s: Next() = %s, expected %s", test.name, next, test.expected)
+		}
+	}
+}
+
+func TestPollerObserve(t *testing.T) {
+	adaptive := &config.Adaptive{MinInterval: 15, MaxInterval: 100}
+
+	tests := []struct {
+		name     string
+		options  config.Options
+		newPosts []int
+		expected time.Duration
+	}{
+		{"activity halves the interval", config.Options{Interval: 60, Adaptive: adaptive}, []int{3}, 30 * time.Second},
+		{"quiet backs off by half", config.Options{Interval: 60, Adaptive: adaptive}, []int{0}, 90 * time.Second},
+		{"activity stops at the minimum", config.Options{Interval: 60, Adaptive: adaptive}, []int{1, 1, 1, 1}, 15 * time.Second},
+		{"quiet stops at the maximum", config.Options{Interval: 60, Adaptive: adaptive}, []int{0, 0, 0}, 100 * time.Second},
+		{"backs off again after activity", config.Options{Interval: 60, Adaptive: adaptive}, []int{5, 5, 0}, 22500 * time.Millisecond},
+		{"minimum below the minimum interval", config.Options{Interval: 60, Adaptive: &config.Adaptive{MinInterval: 1, MaxInterval: 100}}, []int{1, 1, 1, 1, 1, 1}, config.MinimumInterval * time.Second},
+		{"fixed interval ignores activity", config.Options{Interval: 60}, []int{1, 1, 0}, time.Minute},
+	}
+	for _, test := range tests {
+		poller := NewPoller(&test.options)
+		for _, newPosts := range test.newPosts {
+			poller.Observe(newPosts)
+		}
+		if next := poller.Next(time.Now()); next != test.expected {
+			t.Errorf("%s: Next() = %s, expected %s", test.name, next, test.expected)
+		}
+	}
+}
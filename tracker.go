@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"xenigo/internal/reddit"
+)
+
+// UpdateTracker remembers the posts sent for a target so they can be re-checked for
+// edits, deletions, removals and flair changes for a limited window after sending.
+type UpdateTracker struct {
+	window time.Duration
+	posts  map[string]*trackedPost
+	mu     sync.Mutex
+}
+
+type trackedPost struct {
+	post      reddit.RedditPost
+	messageID string
+	sentAt    time.Time
+}
+
+func NewUpdateTracker(window time.Duration) *UpdateTracker {
+	return &UpdateTracker{
+		window: window,
+		posts:  make(map[string]*trackedPost),
+	}
+}
+
+// Track starts tracking a sent post, only link posts can be re-checked via /api/info.
+func (t *UpdateTracker) Track(post reddit.RedditPost, messageID string) {
+	if !strings.HasPrefix(post.Name, reddit.KindLink+"_") {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.posts[post.Name] = &trackedPost{
+		post:      post,
+		messageID: messageID,
+		sentAt:    time.Now(),
+	}
+}
+
+// Names returns the fullnames of all tracked posts, dropping the ones outside the tracking window.
+func (t *UpdateTracker) Names() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var names []string
+	for name, tracked := range t.posts {
+		if time.Since(tracked.sentAt) > t.window {
+			delete(t.posts, name)
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// Update compares the current state of a post with the last seen state and returns the changes
+// together with the id of the originally sent message. Deleted and removed posts are no longer tracked.
+func (t *UpdateTracker) Update(post reddit.RedditPost) ([]string, string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tracked, ok := t.posts[post.Name]
+	if !ok {
+		return nil, ""
+	}
+
+	changes := diffPost(tracked.post, post)
+	tracked.post = post
+	if post.IsDeleted() || post.IsRemoved() {
+		delete(t.posts, post.Name)
+	}
+	return changes, tracked.messageID
+}
+
+func diffPost(previous, current reddit.RedditPost) []string {
+	switch {
+	case current.IsDeleted() && !previous.IsDeleted():
+		return []string{"Post was deleted by its author"}
+	case current.IsRemoved() && !previous.IsRemoved():
+		reason := "moderators"
+		if current.RemovedByCategory != nil && *current.RemovedByCategory != "" {
+			reason = *current.RemovedByCategory
+		}
+		return []string{fmt.Sprintf("Post was removed (%s)", reason)}
+	}
+
+	var changes []string
+	if current.LinkFlairText != previous.LinkFlairText {
+		changes = append(changes, fmt.Sprintf("Flair changed from %q to %q", previous.LinkFlairText, current.LinkFlairText))
+	}
+	if current.Selftext != previous.Selftext {
+		changes = append(changes, "Post was edited")
+	}
+	return changes
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+	"xenigo/internal/reddit"
+)
+
+func TestDiffPost(t *testing.T) {
+	removed, deleted := "moderator", "deleted"
+	previous := reddit.RedditPost{Name: "t3_a", Selftext: "body", LinkFlairText: "Selling"}
+
+	tests := []struct {
+		name     string
+		current  reddit.RedditPost
+		expected []string
+	}{
+		{"unchanged", previous, nil},
+		{"edited", reddit.RedditPost{Name: "t3_a", Selftext: "new body", LinkFlairText: "Selling"}, []string{"Post was edited"}},
+		{"flair", reddit.RedditPost{Name: "t3_a", Selftext: "body", LinkFlairText: "Sold"}, []string{`Flair changed from "Selling" to "Sold"`}},
+		{"flair and edit", reddit.RedditPost{Name: "t3_a", Selftext: "sold", LinkFlairText: "Sold"}, []string{`Flair changed from "Selling" to "Sold"`, "Post was edited"}},
+		{"deleted", reddit.RedditPost{Name: "t3_a", Selftext: "[deleted]", Author: "[deleted]", RemovedByCategory: &deleted}, []string{"Post was deleted by its author"}},
+		{"removed", reddit.RedditPost{Name: "t3_a", Selftext: "[removed]", RemovedByCategory: &removed}, []string{"Post was removed (moderator)"}},
+		{"removed without category", reddit.RedditPost{Name: "t3_a", Selftext: "[removed]", LinkFlairText: "Selling"}, []string{"Post was removed (moderators)"}},
+	}
+	for _, test := range tests {
+		if changes := diffPost(previous, test.current); !reflect.DeepEqual(changes, test.expected) {
+			t.Errorf("%s: diffPost() = %q, expected %q", test.name, changes, test.expected)
+		}
+	}
+}
+
+func TestUpdateTracker(t *testing.T) {
+	tracker := NewUpdateTracker(time.Hour)
+	tracker.Track(reddit.RedditPost{Name: "t3_a", Selftext: "body"}, "message-a")
+	tracker.Track(reddit.RedditPost{Name: "t1_comment", Body: "comment"}, "message-c")
+
+	if names := tracker.Names(); !reflect.DeepEqual(names, []string{"t3_a"}) {
+		t.Fatalf("Names() = %v, expected only the link post", names)
+	}
+
+	changes, messageID := tracker.Update(reddit.RedditPost{Name: "t3_a", Selftext: "edited"})
+	if len(changes) != 1 || messageID != "message-a" {
+		t.Errorf("Update() = %v, %q", changes, messageID)
+	}
+	// The edit is remembered, so it is only reported once
+	if changes, _ := tracker.Update(reddit.RedditPost{Name: "t3_a", Selftext: "edited"}); len(changes) != 0 {
+		t.Errorf("Update() reported %v again", changes)
+	}
+	if changes, messageID := tracker.Update(reddit.RedditPost{Name: "t3_unknown"}); changes != nil || messageID != "" {
+		t.Errorf("Update() of an untracked post = %v, %q", changes, messageID)
+	}
+
+	// Removed posts are reported once and no longer tracked
+	if changes, _ := tracker.Update(reddit.RedditPost{Name: "t3_a", Selftext: "[removed]"}); len(changes) != 1 {
+		t.Errorf("Update() of a removed post = %v", changes)
+	}
+	if names := tracker.Names(); len(names) != 0 {
+		t.Errorf("Names() = %v after removal, expected none", names)
+	}
+}
+
+func TestUpdateTrackerWindow(t *testing.T) {
+	tracker := NewUpdateTracker(time.Minute)
+	tracker.Track(reddit.RedditPost{Name: "t3_old"}, "")
+	tracker.Track(reddit.RedditPost{Name: "t3_new"}, "")
+	tracker.posts["t3_old"].sentAt = time.Now().Add(-2 * time.Minute)
+
+	if names := tracker.Names(); !reflect.DeepEqual(names, []string{"t3_new"}) {
+		t.Errorf("Names() = %v, expected the post outside the window to be dropped", names)
+	}
+	if _, ok := tracker.posts["t3_old"]; ok {
+		t.Error("post outside the window is still tracked")
+	}
+}
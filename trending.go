@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"xenigo/internal/config"
+	"xenigo/internal/reddit"
+)
+
+const (
+	// Posts keep being measured for this long after they were first seen, or for the window when it is longer
+	trendingTrackingPeriod = 24 * time.Hour
+	// At most one /api/info request worth of posts is tracked per target
+	maxTrendingPosts = 100
+)
+
+// TrendTracker records score and comment snapshots of the posts seen across polls of a target
+// and reports when a post gains enough upvotes or comments within the configured window.
+type TrendTracker struct {
+	thresholds config.Trending
+	window     time.Duration
+	period     time.Duration
+	posts      map[string]*trendingPost
+	mu         sync.Mutex
+}
+
+type trendingPost struct {
+	name          string
+	firstSeen     time.Time
+	snapshots     []postSnapshot
+	scoreFired    bool
+	commentsFired bool
+}
+
+type postSnapshot struct {
+	at          time.Time
+	score       int
+	numComments int
+}
+
+func NewTrendTracker(thresholds config.Trending) *TrendTracker {
+	window := time.Duration(thresholds.Window) * time.Second
+	return &TrendTracker{
+		thresholds: thresholds,
+		window:     window,
+		period:     max(window, trendingTrackingPeriod),
+		posts:      make(map[string]*trendingPost),
+	}
+}
+
+// Observe records a snapshot of the post and returns the thresholds it crossed for the first time.
+func (t *TrendTracker) Observe(post reddit.RedditPost) []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	tracked, ok := t.posts[post.Key()]
+	if !ok {
+		if len(t.posts) >= maxTrendingPosts {
+			t.evictOldest()
+		}
+		tracked = &trendingPost{name: post.Name, firstSeen: now}
+		// A post starts out without votes or comments, so posts younger than the window
+		// are measured from their creation time
+		if created := time.Unix(int64(post.CreatedUTC), 0); post.CreatedUTC > 0 && now.Sub(created) <= t.window {
+			tracked.snapshots = append(tracked.snapshots, postSnapshot{at: created})
+		}
+		t.posts[post.Key()] = tracked
+	}
+	tracked.snapshots = append(tracked.snapshots, postSnapshot{at: now, score: post.Score, numComments: post.NumComments})
+
+	// Drop the snapshots that fell out of the window, the oldest remaining one is the baseline
+	for len(tracked.snapshots) > 1 && now.Sub(tracked.snapshots[0].at) > t.window {
+		tracked.snapshots = tracked.snapshots[1:]
+	}
+	baseline := tracked.snapshots[0]
+
+	var reasons []string
+	if gained := post.Score - baseline.score; t.thresholds.Score > 0 && !tracked.scoreFired && gained >= t.thresholds.Score {
+		tracked.scoreFired = true
+		reasons = append(reasons, fmt.Sprintf("+%d upvotes in %s", gained, now.Sub(baseline.at).Round(time.Minute)))
+	}
+	if gained := post.NumComments - baseline.numComments; t.thresholds.Comments > 0 && !tracked.commentsFired && gained >= t.thresholds.Comments {
+		tracked.commentsFired = true
+		reasons = append(reasons, fmt.Sprintf("+%d comments in %s", gained, now.Sub(baseline.at).Round(time.Minute)))
+	}
+	return reasons
+}
+
+// Names returns the fullnames of the tracked link posts that are not among the observed keys,
+// these dropped out of the listing and are re-fetched via /api/info to keep measuring them.
+func (t *TrendTracker) Names(observed map[string]bool) []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var names []string
+	for key, tracked := range t.posts {
+		if !observed[key] && strings.HasPrefix(tracked.name, reddit.KindLink+"_") {
+			names = append(names, tracked.name)
+		}
+	}
+	return names
+}
+
+// Prune forgets posts once their tracking period passed, a post is never reported twice before that.
+func (t *TrendTracker) Prune() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for key, tracked := range t.posts {
+		if time.Since(tracked.firstSeen) > t.period {
+			delete(t.posts, key)
+		}
+	}
+}
+
+// evictOldest forgets the post that was first seen the longest time ago, the caller holds the lock.
+func (t *TrendTracker) evictOldest() {
+	var oldestKey string
+	var oldest time.Time
+	for key, tracked := range t.posts {
+		if oldestKey == "" || tracked.firstSeen.Before(oldest) {
+			oldestKey, oldest = key, tracked.firstSeen
+		}
+	}
+	delete(t.posts, oldestKey)
+}
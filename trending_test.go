@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+	"xenigo/internal/config"
+	"xenigo/internal/reddit"
+)
+
+// advance moves the snapshots of all posts back in time, as if the duration passed
+func (t *TrendTracker) advance(d time.Duration) {
+	for _, tracked := range t.posts {
+		tracked.firstSeen = tracked.firstSeen.Add(-d)
+		for i := range tracked.snapshots {
+			tracked.snapshots[i].at = tracked.snapshots[i].at.Add(-d)
+		}
+	}
+}
+
+func TestTrendTracker(t *testing.T) {
+	type poll struct {
+		after    time.Duration // Time passed since the previous poll
+		score    int
+		comments int
+		prune    time.Duration // Time passed before pruning after the poll
+		expected int           // Number of thresholds crossed
+	}
+	old := float64(time.Now().Add(-24 * time.Hour).Unix())
+	young := float64(time.Now().Add(-10 * time.Minute).Unix())
+
+	tests := []struct {
+		name    string
+		created float64
+		polls   []poll
+	}{
+		{"old post is measured from first sight", old, []poll{
+			{score: 500, comments: 200},
+			{after: 10 * time.Minute, score: 520, comments: 210},
+			{after: 10 * time.Minute, score: 600, comments: 210, expected: 1},
+		}},
+		{"young post is measured from creation", young, []poll{
+			{score: 150, comments: 60, expected: 2},
+		}},
+		{"threshold crossed twice fires once", old, []poll{
+			{score: 10},
+			{after: 10 * time.Minute, score: 120, expected: 1},
+			{after: 10 * time.Minute, score: 250},
+			{after: 10 * time.Minute, score: 400, comments: 60, expected: 1},
+		}},
+		{"gain spread beyond the window", old, []poll{
+			{score: 10},
+			{after: 40 * time.Minute, score: 60},
+			{after: 40 * time.Minute, score: 115},
+		}},
+		{"post that left the listing does not fire again", old, []poll{
+			{score: 10},
+			{after: 10 * time.Minute, score: 120, expected: 1},
+			{after: 10 * time.Minute, score: 130, prune: 2 * time.Hour},
+			{score: 500},
+		}},
+		{"aged out post starts over", old, []poll{
+			{score: 10},
+			{after: 10 * time.Minute, score: 120, expected: 1, prune: 25 * time.Hour},
+			{score: 520},
+			{after: 10 * time.Minute, score: 650, expected: 1},
+		}},
+	}
+	for _, test := range tests {
+		tracker := NewTrendTracker(config.Trending{Score: 100, Comments: 50, Window: 3600})
+		post := reddit.RedditPost{Name: "t3_a", Permalink: "/r/test/comments/a", CreatedUTC: test.created}
+		for i, p := range test.polls {
+			tracker.advance(p.after)
+			post.Score, post.NumComments = p.score, p.comments
+			if reasons := tracker.Observe(post); len(reasons) != p.expected {
+				t.Errorf("%s: poll %d crossed %q, expected %d thresholds", test.name, i, reasons, p.expected)
+			}
+			if p.prune > 0 {
+				tracker.advance(p.prune)
+				tracker.Prune()
+			}
+		}
+	}
+}
+
+func TestTrendTrackerPrune(t *testing.T) {
+	tracker := NewTrendTracker(config.Trending{Score: 100, Window: 3600})
+	tracker.Observe(reddit.RedditPost{Permalink: "/r/test/comments/aged"})
+	tracker.advance(23 * time.Hour)
+	tracker.Observe(reddit.RedditPost{Permalink: "/r/test/comments/unlisted"})
+	tracker.advance(2 * time.Hour)
+	tracker.Observe(reddit.RedditPost{Permalink: "/r/test/comments/aged"})
+	tracker.Prune()
+
+	if _, ok := tracker.posts["/r/test/comments/aged"]; ok {
+		t.Error("post past its tracking period was kept")
+	}
+	if _, ok := tracker.posts["/r/test/comments/unlisted"]; !ok {
+		t.Error("post within its tracking period was pruned")
+	}
+}
+
+func TestTrendTrackerNames(t *testing.T) {
+	tracker := NewTrendTracker(config.Trending{Score: 100, Window: 3600})
+	tracker.Observe(reddit.RedditPost{Name: "t3_listed", Permalink: "/r/test/comments/listed"})
+	tracker.Observe(reddit.RedditPost{Name: "t3_unlisted", Permalink: "/r/test/comments/unlisted"})
+	tracker.Observe(reddit.RedditPost{Name: "t1_comment", Permalink: "/r/test/comments/listed/comment"})
+
+	names := tracker.Names(map[string]bool{"/r/test/comments/listed": true})
+	if len(names) != 1 || names[0] != "t3_unlisted" {
+		t.Errorf("Names() = %q, expected only the link post missing from the listing", names)
+	}
+}
+
+func TestTrendTrackerEvictsOldest(t *testing.T) {
+	tracker := NewTrendTracker(config.Trending{Score: 100, Window: 3600})
+	tracker.Observe(reddit.RedditPost{Permalink: "/r/test/comments/oldest"})
+	tracker.advance(time.Minute)
+	for i := 1; i < maxTrendingPosts; i++ {
+		tracker.Observe(reddit.RedditPost{Permalink: fmt.Sprintf("/r/test/comments/%d", i)})
+	}
+	tracker.Observe(reddit.RedditPost{Permalink: "/r/test/comments/newest"})
+
+	if len(tracker.posts) != maxTrendingPosts {
+		t.Errorf("tracker holds %d posts, expected %d", len(tracker.posts), maxTrendingPosts)
+	}
+	if _, ok := tracker.posts["/r/test/comments/oldest"]; ok {
+		t.Error("oldest post was not evicted")
+	}
+}